@@ -0,0 +1,222 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+)
+
+// Config is the configuration form of the built-in Options, so a Checker can
+// be described in a JSON or YAML file instead of code.  The zero value
+// builds a Checker without any rules.  Options that need code, such as
+// resolvers and custom vadors, can be added with Build.
+type Config struct {
+	// OnlyAllowSchemes is the list of allowed schemes.  See OnlyAllowSchemes.
+	OnlyAllowSchemes []string `json:"only_allow_schemes,omitempty" yaml:"only_allow_schemes,omitempty"`
+
+	// OnlyAllowPorts is the list of allowed ports and port ranges.  See
+	// OnlyAllowPorts.
+	OnlyAllowPorts []string `json:"only_allow_ports,omitempty" yaml:"only_allow_ports,omitempty"`
+
+	// ForbidPorts is the list of forbidden ports and port ranges.  See
+	// ForbidPorts.
+	ForbidPorts []string `json:"forbid_ports,omitempty" yaml:"forbid_ports,omitempty"`
+
+	// ForbidSchemePortMismatch enables ForbidSchemePortMismatch.
+	ForbidSchemePortMismatch bool `json:"forbid_scheme_port_mismatch,omitempty" yaml:"forbid_scheme_port_mismatch,omitempty"`
+
+	// ForbidUserinfo enables ForbidUserinfo.
+	ForbidUserinfo bool `json:"forbid_userinfo,omitempty" yaml:"forbid_userinfo,omitempty"`
+
+	// ForbidPassword enables ForbidPassword.
+	ForbidPassword bool `json:"forbid_password,omitempty" yaml:"forbid_password,omitempty"`
+
+	// ForbidPathTraversal enables ForbidPathTraversal.
+	ForbidPathTraversal bool `json:"forbid_path_traversal,omitempty" yaml:"forbid_path_traversal,omitempty"`
+
+	// OnlyAllowPaths is the list of allowed path patterns.  See
+	// OnlyAllowPaths.
+	OnlyAllowPaths []string `json:"only_allow_paths,omitempty" yaml:"only_allow_paths,omitempty"`
+
+	// ForbidPaths is the list of forbidden path patterns.  See ForbidPaths.
+	ForbidPaths []string `json:"forbid_paths,omitempty" yaml:"forbid_paths,omitempty"`
+
+	// MaxQueryParams is the maximum number of query parameters, if set.  See
+	// MaxQueryParams.
+	MaxQueryParams *int `json:"max_query_params,omitempty" yaml:"max_query_params,omitempty"`
+
+	// ForbidFragment enables ForbidFragment.
+	ForbidFragment bool `json:"forbid_fragment,omitempty" yaml:"forbid_fragment,omitempty"`
+
+	// OnlyAllowDomainNames is the list of allowed domain name patterns and
+	// IP literal subnets.  See OnlyAllowDomainNames.
+	OnlyAllowDomainNames []string `json:"only_allow_domain_names,omitempty" yaml:"only_allow_domain_names,omitempty"`
+
+	// ForbidDomainNames is the list of forbidden domain name patterns.  See
+	// ForbidDomainNames.
+	ForbidDomainNames []string `json:"forbid_domain_names,omitempty" yaml:"forbid_domain_names,omitempty"`
+
+	// ForbidSpecialUseDomains enables ForbidSpecialUseDomains.
+	ForbidSpecialUseDomains bool `json:"forbid_special_use_domains,omitempty" yaml:"forbid_special_use_domains,omitempty"`
+
+	// ForbidPublicSuffixes enables ForbidPublicSuffixes.
+	ForbidPublicSuffixes bool `json:"forbid_public_suffixes,omitempty" yaml:"forbid_public_suffixes,omitempty"`
+
+	// ForbidInvalidIDN enables ForbidInvalidIDN.
+	ForbidInvalidIDN bool `json:"forbid_invalid_idn,omitempty" yaml:"forbid_invalid_idn,omitempty"`
+
+	// ForbidConfusableHosts enables ForbidConfusableHosts.  It is also
+	// enabled if any ConfusableProtectedDomains are set.
+	ForbidConfusableHosts bool `json:"forbid_confusable_hosts,omitempty" yaml:"forbid_confusable_hosts,omitempty"`
+
+	// ConfusableProtectedDomains is the list of domains that a hostname may
+	// not be confusable with.  See ForbidConfusableHosts.
+	ConfusableProtectedDomains []string `json:"confusable_protected_domains,omitempty" yaml:"confusable_protected_domains,omitempty"`
+
+	// ForbidLoopback enables ForbidLoopback.
+	ForbidLoopback bool `json:"forbid_loopback,omitempty" yaml:"forbid_loopback,omitempty"`
+
+	// ForbidAnyIPs enables ForbidAnyIPs.
+	ForbidAnyIPs bool `json:"forbid_any_ips,omitempty" yaml:"forbid_any_ips,omitempty"`
+
+	// ForbidLegacyIPv4 enables ForbidLegacyIPv4.
+	ForbidLegacyIPv4 bool `json:"forbid_legacy_ipv4,omitempty" yaml:"forbid_legacy_ipv4,omitempty"`
+
+	// CheckEmbeddedIPv4 enables CheckEmbeddedIPv4.
+	CheckEmbeddedIPv4 bool `json:"check_embedded_ipv4,omitempty" yaml:"check_embedded_ipv4,omitempty"`
+
+	// ForbidSubnets is the list of forbidden subnets.  See ForbidSubnets.
+	ForbidSubnets []string `json:"forbid_subnets,omitempty" yaml:"forbid_subnets,omitempty"`
+
+	// OnlyAllowSubnets is the list of allowed subnets.  See OnlyAllowSubnets.
+	OnlyAllowSubnets []string `json:"only_allow_subnets,omitempty" yaml:"only_allow_subnets,omitempty"`
+
+	// ForbidPrivateNetworks enables ForbidPrivateNetworks.
+	ForbidPrivateNetworks bool `json:"forbid_private_networks,omitempty" yaml:"forbid_private_networks,omitempty"`
+
+	// ForbidSpecialUseIPs enables ForbidSpecialUseIPs.
+	ForbidSpecialUseIPs bool `json:"forbid_special_use_ips,omitempty" yaml:"forbid_special_use_ips,omitempty"`
+
+	// ForbidCloudMetadata enables ForbidCloudMetadata.
+	ForbidCloudMetadata bool `json:"forbid_cloud_metadata,omitempty" yaml:"forbid_cloud_metadata,omitempty"`
+
+	// MaxRedirects is the maximum number of redirects CheckRedirect allows,
+	// if set.  See MaxRedirects.
+	MaxRedirects *int `json:"max_redirects,omitempty" yaml:"max_redirects,omitempty"`
+
+	// WHATWGParser enables parsing with the WHATWG parser.  See WHATWG.
+	WHATWGParser bool `json:"whatwg_parser,omitempty" yaml:"whatwg_parser,omitempty"`
+}
+
+// Build returns a new Checker with the configured Options applied, followed
+// by any additional Options.  If a configured value is invalid, the error
+// names the config field, such as "forbid_subnets[1]", and wraps the error
+// from the Option.
+func (cfg Config) Build(opts ...Option) (*Checker, error) {
+	var b configBuilder
+
+	b.list("only_allow_schemes", cfg.OnlyAllowSchemes, OnlyAllowSchemes)
+	b.list("only_allow_ports", cfg.OnlyAllowPorts, OnlyAllowPorts)
+	b.list("forbid_ports", cfg.ForbidPorts, ForbidPorts)
+	b.flag(cfg.ForbidSchemePortMismatch, ForbidSchemePortMismatch)
+	b.flag(cfg.ForbidUserinfo, ForbidUserinfo)
+	b.flag(cfg.ForbidPassword, ForbidPassword)
+	b.flag(cfg.ForbidPathTraversal, ForbidPathTraversal)
+	b.list("only_allow_paths", cfg.OnlyAllowPaths, OnlyAllowPaths)
+	b.list("forbid_paths", cfg.ForbidPaths, ForbidPaths)
+	if cfg.MaxQueryParams != nil {
+		b.add("max_query_params", MaxQueryParams(*cfg.MaxQueryParams))
+	}
+	b.flag(cfg.ForbidFragment, ForbidFragment)
+
+	b.list("only_allow_domain_names", cfg.OnlyAllowDomainNames, OnlyAllowDomainNames)
+	b.list("forbid_domain_names", cfg.ForbidDomainNames, ForbidDomainNames)
+	b.flag(cfg.ForbidSpecialUseDomains, ForbidSpecialUseDomains)
+	b.flag(cfg.ForbidPublicSuffixes, ForbidPublicSuffixes)
+	b.flag(cfg.ForbidInvalidIDN, ForbidInvalidIDN)
+	if len(cfg.ConfusableProtectedDomains) > 0 {
+		b.list("confusable_protected_domains", cfg.ConfusableProtectedDomains, ForbidConfusableHosts)
+	} else if cfg.ForbidConfusableHosts {
+		b.add("forbid_confusable_hosts", ForbidConfusableHosts())
+	}
+
+	b.flag(cfg.ForbidLoopback, ForbidLoopback)
+	b.flag(cfg.ForbidAnyIPs, ForbidAnyIPs)
+	b.flag(cfg.ForbidLegacyIPv4, ForbidLegacyIPv4)
+	b.flag(cfg.CheckEmbeddedIPv4, CheckEmbeddedIPv4)
+	b.list("forbid_subnets", cfg.ForbidSubnets, func(subnets ...string) Option {
+		return ForbidSubnets(subnets)
+	})
+	b.list("only_allow_subnets", cfg.OnlyAllowSubnets, func(subnets ...string) Option {
+		return OnlyAllowSubnets(subnets)
+	})
+	b.flag(cfg.ForbidPrivateNetworks, ForbidPrivateNetworks)
+	b.flag(cfg.ForbidSpecialUseIPs, ForbidSpecialUseIPs)
+	b.flag(cfg.ForbidCloudMetadata, ForbidCloudMetadata)
+
+	if cfg.MaxRedirects != nil {
+		b.add("max_redirects", MaxRedirects(*cfg.MaxRedirects))
+	}
+	if cfg.WHATWGParser {
+		b.add("whatwg_parser", WithParser(WHATWG))
+	}
+
+	if b.err != nil {
+		return nil, b.err
+	}
+	return New(append(b.opts, opts...)...)
+}
+
+// configBuilder collects the Options for a Config, stopping at the first
+// invalid value.
+type configBuilder struct {
+	opts []Option
+	err  error
+}
+
+// add adds the Option, or records its error against the config field.
+func (b *configBuilder) add(path string, opt Option) {
+	if b.err != nil {
+		return
+	}
+	if err := optionErr(opt); err != nil {
+		b.err = fmt.Errorf("%s: %w", path, err)
+		return
+	}
+	b.opts = append(b.opts, opt)
+}
+
+// flag adds the Option if the config field is set.
+func (b *configBuilder) flag(set bool, opt func() Option) {
+	if set {
+		b.opts = append(b.opts, opt())
+	}
+}
+
+// list adds the Option built from the list if the list is not empty.  Each
+// item is checked on its own first, so an invalid item is reported with its
+// index.
+func (b *configBuilder) list(path string, items []string, opt func(...string) Option) {
+	if b.err != nil || len(items) == 0 {
+		return
+	}
+
+	for i, item := range items {
+		if err := optionErr(opt(item)); err != nil {
+			b.err = fmt.Errorf("%s[%d]: %w", path, i, err)
+			return
+		}
+	}
+
+	// Some Options modify the list they are given, so the Config is not
+	// changed by building it.
+	b.add(path, opt(append([]string(nil), items...)...))
+}
+
+// optionErr returns the error an Option will cause New to return, if any.
+func optionErr(opt Option) error {
+	var c Checker
+	opt.apply(&c)
+	return c.err
+}
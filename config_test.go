@@ -0,0 +1,188 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigBuild(t *testing.T) {
+	in := `{
+		"only_allow_schemes": ["HTTPS"],
+		"only_allow_ports": ["443", "8443-8449"],
+		"forbid_ports": ["8444"],
+		"forbid_scheme_port_mismatch": true,
+		"forbid_userinfo": true,
+		"forbid_password": true,
+		"forbid_path_traversal": true,
+		"only_allow_paths": ["/api/**", "/"],
+		"forbid_paths": ["/api/admin/**"],
+		"max_query_params": 2,
+		"forbid_fragment": true,
+		"only_allow_domain_names": ["**.acme.com", "!=internal.acme.com", "10.0.0.0/8"],
+		"forbid_domain_names": ["bad.acme.com"],
+		"forbid_special_use_domains": true,
+		"forbid_public_suffixes": true,
+		"forbid_invalid_idn": true,
+		"forbid_confusable_hosts": true,
+		"confusable_protected_domains": ["acme.com"],
+		"forbid_loopback": true,
+		"forbid_any_ips": true,
+		"forbid_legacy_ipv4": true,
+		"check_embedded_ipv4": true,
+		"forbid_subnets": ["10.1.0.0/16"],
+		"only_allow_subnets": ["10.0.0.0/8"],
+		"forbid_private_networks": true,
+		"forbid_special_use_ips": true,
+		"forbid_cloud_metadata": true,
+		"max_redirects": 0,
+		"whatwg_parser": true
+	}`
+
+	var cfg Config
+	require.NoError(t, json.Unmarshal([]byte(in), &cfg))
+
+	c, err := cfg.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "urlegit.Checker{ "+
+		"OnlyAllowSchemes('https'), "+
+		"OnlyAllowPorts('443', '8443-8449'), "+
+		"ForbidPorts('8444'), "+
+		"ForbidSchemePortMismatch(), "+
+		"ForbidUserinfo(), "+
+		"ForbidPassword(), "+
+		"ForbidPathTraversal(), "+
+		"OnlyAllowPaths('/api/**', '/'), "+
+		"ForbidPaths('/api/admin/**'), "+
+		"MaxQueryParams(2), "+
+		"ForbidFragment(), "+
+		"OnlyAllowDomainNames('**.acme.com', '!=internal.acme.com', '10.0.0.0/8'), "+
+		"ForbidDomainNames('bad.acme.com'), "+
+		"ForbidSpecialUseDomains('*.alt', '*.example', '*.invalid', '*.local', '*.localhost', '*.test', 'example.*'), "+
+		"ForbidPublicSuffixes(), "+
+		"ForbidInvalidIDN(), "+
+		"ForbidConfusableHosts('acme.com'), "+
+		"ForbidLoopback(), "+
+		"ForbidAnyIPs(), "+
+		"ForbidLegacyIPv4(), "+
+		"CheckEmbeddedIPv4(), "+
+		"ForbidSubnets('10.1.0.0/16'), "+
+		"OnlyAllowSubnets('10.0.0.0/8'), "+
+		"ForbidPrivateNetworks(), "+
+		"ForbidSpecialUseIPs(), "+
+		"ForbidCloudMetadata(), "+
+		"MaxRedirects(0), "+
+		"WithParser(parser) }", c.String())
+
+	// The config is not modified by building it.
+	assert.Equal(t, []string{"HTTPS"}, cfg.OnlyAllowSchemes)
+
+	assert.NoError(t, c.Text("https://www.acme.com/api/users?a=1"))
+	assert.False(t, c.Legit("http://www.acme.com/"))
+	assert.False(t, c.Legit("https://www.acme.com:80/"))
+	assert.False(t, c.Legit("https://www.acme.com:8444/"))
+	assert.False(t, c.Legit("https://user@www.acme.com/"))
+	assert.False(t, c.Legit("https://www.acme.com/api/../admin"))
+	assert.False(t, c.Legit("https://www.acme.com/api/admin/users"))
+	assert.False(t, c.Legit("https://www.acme.com/?a=1&b=2&c=3"))
+	assert.False(t, c.Legit("https://www.acme.com/#top"))
+	assert.False(t, c.Legit("https://internal.acme.com/"))
+	assert.False(t, c.Legit("https://bad.acme.com/"))
+	assert.False(t, c.Legit("https://acme.org/"))
+	assert.False(t, c.Legit("https://10.0.0.1/"))
+	assert.ErrorIs(t, c.Text("https://0x7f.1/"), ErrLegacyIPv4)
+
+	req, err := http.NewRequest(http.MethodGet, "https://www.acme.com/", nil)
+	require.NoError(t, err)
+	assert.ErrorIs(t, c.CheckRedirect(req, []*http.Request{req}), ErrTooManyRedirects)
+}
+
+func TestConfigBuildEmpty(t *testing.T) {
+	c, err := Config{}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "urlegit.Checker{}", c.String())
+	assert.True(t, c.Legit("gopher://example.com"))
+}
+
+func TestConfigBuildOptions(t *testing.T) {
+	cfg := Config{
+		OnlyAllowSchemes: []string{"http"},
+		ForbidSubnets:    []string{"192.168.0.0/16"},
+	}
+
+	c, err := cfg.Build(ForbidSubnet("10.0.0.0/8", mockResolver))
+	require.NoError(t, err)
+	assert.False(t, c.Legit("http://10.0.0.1"))
+	assert.True(t, c.Legit(mockLoopbackURL))
+}
+
+func TestConfigBuildConfusableHosts(t *testing.T) {
+	c, err := Config{ForbidConfusableHosts: true}.Build()
+	require.NoError(t, err)
+	assert.Equal(t, "urlegit.Checker{ ForbidConfusableHosts() }", c.String())
+}
+
+func TestConfigBuildErrors(t *testing.T) {
+	negative := -1
+
+	tests := []struct {
+		description string
+		cfg         Config
+		expected    string
+	}{
+		{
+			description: "invalid subnet",
+			cfg:         Config{ForbidSubnets: []string{"10.0.0.0/8", "10.0.0.0/33"}},
+			expected:    "forbid_subnets[1]: invalid input: invalid subnet '10.0.0.0/33'",
+		}, {
+			description: "invalid allowed subnet",
+			cfg:         Config{OnlyAllowSubnets: []string{"fd00::/129"}},
+			expected:    "only_allow_subnets[0]: invalid input: invalid subnet 'fd00::/129'",
+		}, {
+			description: "invalid domain",
+			cfg:         Config{ForbidDomainNames: []string{"example.com", "bad..com"}},
+			expected:    "forbid_domain_names[1]: invalid input: invalid domain 'bad..com' zero length subdomain",
+		}, {
+			description: "invalid allowed domain",
+			cfg:         Config{OnlyAllowDomainNames: []string{"10.0.0.0/8", "*x.example.com"}},
+			expected:    "only_allow_domain_names[1]: invalid input: invalid domain '*x.example.com' '*' must be a whole label",
+		}, {
+			description: "invalid protected domain",
+			cfg:         Config{ConfusableProtectedDomains: []string{""}},
+			expected:    "confusable_protected_domains[0]: invalid input: invalid domain ''",
+		}, {
+			description: "invalid port",
+			cfg:         Config{OnlyAllowPorts: []string{"443", "0"}},
+			expected:    "only_allow_ports[1]: invalid input: invalid port range '0'",
+		}, {
+			description: "invalid path",
+			cfg:         Config{ForbidPaths: []string{"/["}},
+			expected:    "forbid_paths[0]: invalid input: invalid path pattern '/[' syntax error in pattern",
+		}, {
+			description: "invalid max query params",
+			cfg:         Config{MaxQueryParams: &negative},
+			expected:    "max_query_params: invalid input: negative max query parameters -1",
+		}, {
+			description: "invalid max redirects",
+			cfg:         Config{MaxRedirects: &negative},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			c, err := tc.cfg.Build()
+			assert.Nil(t, c)
+			assert.ErrorIs(t, err, ErrInvalidInput)
+			if tc.expected != "" {
+				assert.EqualError(t, err, tc.expected)
+			} else {
+				assert.Contains(t, err.Error(), "max_redirects: ")
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+// confusables maps characters to their prototype.  It is a hand-picked
+// subset of the Unicode TR39 confusables.txt data, not a copy of it: only
+// common lookalikes of ASCII letters and digits that survive the UTS #46
+// mapping are included, so a hostname built from a confusable that is not
+// listed here will not be caught.  Add entries as they are found.
+//
+// The skeleton is built from the lowercase Unicode form of the hostname, so
+// only lowercase characters can match and the prototypes are lowercase.
+//
+// See https://www.unicode.org/Public/security/latest/confusables.txt
+var confusables = map[rune]string{
+	// ASCII
+	'0': "o",
+	'1': "l",
+	'|': "l",
+	'm': "rn",
+
+	// Latin
+	'ı': "i", // LATIN SMALL LETTER DOTLESS I
+	'ȷ': "j", // LATIN SMALL LETTER DOTLESS J
+	'ɑ': "a", // LATIN SMALL LETTER ALPHA
+	'ɡ': "g", // LATIN SMALL LETTER SCRIPT G
+	'ɩ': "i", // LATIN SMALL LETTER IOTA
+	'ɪ': "i", // LATIN LETTER SMALL CAPITAL I
+	'ʏ': "y", // LATIN LETTER SMALL CAPITAL Y
+	'ǀ': "l", // LATIN LETTER DENTAL CLICK
+	'ᴄ': "c", // LATIN LETTER SMALL CAPITAL C
+	'ᴏ': "o", // LATIN LETTER SMALL CAPITAL O
+	'ᴜ': "u", // LATIN LETTER SMALL CAPITAL U
+	'ᴠ': "v", // LATIN LETTER SMALL CAPITAL V
+	'ᴡ': "w", // LATIN LETTER SMALL CAPITAL W
+	'ᴢ': "z", // LATIN LETTER SMALL CAPITAL Z
+
+	// Greek
+	'α': "a", // GREEK SMALL LETTER ALPHA
+	'γ': "y", // GREEK SMALL LETTER GAMMA
+	'ι': "i", // GREEK SMALL LETTER IOTA
+	'ν': "v", // GREEK SMALL LETTER NU
+	'ο': "o", // GREEK SMALL LETTER OMICRON
+	'ρ': "p", // GREEK SMALL LETTER RHO
+	'σ': "o", // GREEK SMALL LETTER SIGMA
+	'υ': "u", // GREEK SMALL LETTER UPSILON
+
+	// Cyrillic
+	'а': "a", // CYRILLIC SMALL LETTER A
+	'е': "e", // CYRILLIC SMALL LETTER IE
+	'о': "o", // CYRILLIC SMALL LETTER O
+	'р': "p", // CYRILLIC SMALL LETTER ER
+	'с': "c", // CYRILLIC SMALL LETTER ES
+	'у': "y", // CYRILLIC SMALL LETTER U
+	'х': "x", // CYRILLIC SMALL LETTER HA
+	'ѕ': "s", // CYRILLIC SMALL LETTER DZE
+	'і': "i", // CYRILLIC SMALL LETTER BYELORUSSIAN-UKRAINIAN I
+	'ј': "j", // CYRILLIC SMALL LETTER JE
+	'ү': "y", // CYRILLIC SMALL LETTER STRAIGHT U
+	'һ': "h", // CYRILLIC SMALL LETTER SHHA
+	'ӏ': "l", // CYRILLIC SMALL LETTER PALOCHKA
+	'ԁ': "d", // CYRILLIC SMALL LETTER KOMI DE
+	'ԛ': "q", // CYRILLIC SMALL LETTER QA
+	'ԝ': "w", // CYRILLIC SMALL LETTER WE
+
+	// Armenian
+	'հ': "h", // ARMENIAN SMALL LETTER HO
+	'ս': "u", // ARMENIAN SMALL LETTER SEH
+	'ց': "g", // ARMENIAN SMALL LETTER CO
+	'օ': "o", // ARMENIAN SMALL LETTER OH
+}
@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// SafeDialer enforces the rules of a Checker at connect time.  The host
+// being dialed is resolved exactly once, the host and IP rules are run
+// against it, and a connection is only made to an address that passed.
+// Because the IPs that are checked are the IPs that are dialed, this closes
+// the DNS rebinding gap between a pre-flight check and the connection.
+//
+// The DialContext method can be used as http.Transport.DialContext.
+type SafeDialer struct {
+	// Checker provides the rules to enforce.  It must not be nil.
+	Checker *Checker
+
+	// Dialer is used to make the connection.  If nil, a zero value
+	// net.Dialer is used.
+	Dialer *net.Dialer
+
+	// Resolver is used to resolve the host.  If nil, the Checker's resolver
+	// is used, and if the Checker does not have one, net.DefaultResolver is
+	// used.
+	Resolver ResolverContext
+}
+
+// DialContext resolves the host in the address, checks it and connects to
+// the first allowed address that accepts the connection.  If no address is
+// allowed, the errors from the rules that rejected them are returned.
+func (d *SafeDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	host = strings.ToLower(host)
+
+	resolver := d.Resolver
+	if resolver == nil {
+		resolver = d.Checker.resolver
+	}
+	if resolver == nil {
+		resolver = defaultResolver(network)
+	}
+
+	r := run{all: true}
+	_, ips := d.Checker.checkHost(ctx, host, resolver, &r)
+	ips = filterNetwork(network, ips)
+	if len(ips) == 0 {
+		if len(r.errs) == 0 {
+			return nil, fmt.Errorf("%w: '%s'", ErrNoAddress, host)
+		}
+		return nil, errors.Join(r.errs...)
+	}
+
+	dialer := d.Dialer
+	if dialer == nil {
+		dialer = &net.Dialer{}
+	}
+
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// DialContext connects to the address only if it is allowed by the Checker.
+// It is the same as using a SafeDialer with only the Checker set, and can be
+// used as http.Transport.DialContext.
+func (c *Checker) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	d := SafeDialer{Checker: c}
+	return d.DialContext(ctx, network, address)
+}
+
+// defaultResolver returns a ResolverContext based on net.DefaultResolver that
+// only returns the IPs usable by the network.
+func defaultResolver(network string) ResolverContext {
+	family := "ip"
+	switch {
+	case strings.HasSuffix(network, "4"):
+		family = "ip4"
+	case strings.HasSuffix(network, "6"):
+		family = "ip6"
+	}
+
+	return func(ctx context.Context, host string) ([]net.IP, error) {
+		return net.DefaultResolver.LookupIP(ctx, family, host)
+	}
+}
+
+// filterNetwork removes the IPs that cannot be used with the network.
+func filterNetwork(network string, ips []netip.Addr) []netip.Addr {
+	rv := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		is4 := ip.Is4()
+		switch {
+		case strings.HasSuffix(network, "4") && !is4:
+		case strings.HasSuffix(network, "6") && is4:
+		default:
+			rv = append(rv, ip)
+		}
+	}
+	return rv
+}
@@ -0,0 +1,153 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSafeDialer(t *testing.T) {
+	l, err := net.Listen("tcp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	_, port, err := net.SplitHostPort(l.Addr().String())
+	require.NoError(t, err)
+
+	tests := []struct {
+		description string
+		opts        []Option
+		resolver    ResolverContext
+		network     string
+		host        string
+		badAddress  bool
+		expectedErr error
+	}{
+		{
+			description: "ip literal allowed",
+			host:        "127.0.0.1",
+		}, {
+			description: "ip literal forbidden",
+			opts:        []Option{ForbidLoopback()},
+			host:        "127.0.0.1",
+			expectedErr: ErrLoopback,
+		}, {
+			description: "host forbidden",
+			opts:        []Option{ForbidDomainNames("mock-loopback.com"), WithResolver(mockResolver)},
+			host:        getFQDN(mockLoopbackURL),
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "every resolved ip forbidden",
+			opts:        []Option{ForbidLoopback(), WithResolver(mockResolver)},
+			host:        getFQDN(mockLoopbackURL),
+			expectedErr: ErrLoopback,
+		}, {
+			description: "only the allowed resolved ip is dialed",
+			opts:        []Option{ForbidSubnet("192.168.0.0/16"), WithResolver(mockResolver)},
+			host:        getFQDN(mockPrivateLoopbackURL),
+		}, {
+			description: "dialer resolver overrides the checker resolver",
+			opts:        []Option{ForbidSubnet("192.168.0.0/16")},
+			resolver:    mockResolverContext,
+			host:        getFQDN(mockPrivateLoopbackURL),
+		}, {
+			description: "no addresses for the network",
+			resolver:    mockResolverContext,
+			network:     "tcp6",
+			host:        getFQDN(mockLoopbackURL),
+			expectedErr: ErrNoAddress,
+		}, {
+			description: "resolver failure",
+			resolver:    mockResolverContext,
+			host:        getFQDN(mockUnsupportedURL),
+			expectedErr: errAny,
+		}, {
+			description: "invalid address",
+			badAddress:  true,
+			expectedErr: errAny,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert := assert.New(t)
+
+			d := SafeDialer{
+				Checker:  Must(tc.opts...),
+				Resolver: tc.resolver,
+			}
+
+			network := tc.network
+			if network == "" {
+				network = "tcp"
+			}
+
+			address := net.JoinHostPort(tc.host, port)
+			if tc.badAddress {
+				address = "missing-port"
+			}
+
+			conn, err := d.DialContext(context.Background(), network, address)
+			if tc.expectedErr == nil {
+				assert.NoError(err)
+				if assert.NotNil(conn) {
+					conn.Close()
+				}
+				return
+			}
+
+			if tc.expectedErr == errAny {
+				assert.Error(err)
+			} else {
+				assert.ErrorIs(err, tc.expectedErr)
+			}
+			assert.Nil(conn)
+		})
+	}
+}
+
+func TestCheckerDialContextTransport(t *testing.T) {
+	assert := assert.New(t)
+	require := require.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	allowed := http.Client{
+		Transport: &http.Transport{
+			DialContext: Must().DialContext,
+		},
+	}
+	resp, err := allowed.Get(server.URL)
+	require.NoError(err)
+	resp.Body.Close()
+	assert.Equal(http.StatusOK, resp.StatusCode)
+
+	forbidden := http.Client{
+		Transport: &http.Transport{
+			DialContext: Must(ForbidLoopback()).DialContext,
+		},
+	}
+	resp, err = forbidden.Get(server.URL)
+	assert.ErrorIs(err, ErrLoopback)
+	assert.Nil(resp)
+}
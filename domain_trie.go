@@ -0,0 +1,125 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+// domainTrie is a trie of domain name patterns keyed by their reversed
+// labels.  The wildcard labels '*' and '**' have their own edges so a lookup
+// only follows the edges that can match the hostname.
+type domainTrie struct {
+	root  *domainNode
+	count int
+}
+
+type domainNode struct {
+	children map[string]*domainNode
+	star     *domainNode
+	globstar *domainNode
+
+	// suffix is the pattern that ends at this node and matches any
+	// remaining labels.  exact is the pattern that ends at this node and
+	// only matches when no labels remain.  If several patterns end at the
+	// same node, the one added first is kept.
+	suffix      *domainName
+	suffixOrder int
+	exact       *domainName
+	exactOrder  int
+}
+
+func newDomainTrie() *domainTrie {
+	return &domainTrie{
+		root: &domainNode{},
+	}
+}
+
+// Add adds the pattern to the trie.  Patterns added earlier take priority
+// over patterns added later when both match.
+func (t *domainTrie) Add(d *domainName) {
+	n := t.root
+	for _, label := range d.subs {
+		n = n.child(label)
+	}
+
+	if d.exact {
+		if n.exact == nil {
+			n.exact, n.exactOrder = d, t.count
+		}
+	} else if n.suffix == nil {
+		n.suffix, n.suffixOrder = d, t.count
+	}
+	t.count++
+}
+
+func (n *domainNode) child(label string) *domainNode {
+	var next **domainNode
+	switch label {
+	case "*":
+		next = &n.star
+	case "**":
+		next = &n.globstar
+	default:
+		if n.children == nil {
+			n.children = make(map[string]*domainNode)
+		}
+		c, ok := n.children[label]
+		if !ok {
+			c = &domainNode{}
+			n.children[label] = c
+		}
+		return c
+	}
+
+	if *next == nil {
+		*next = &domainNode{}
+	}
+	return *next
+}
+
+// Match returns the earliest added pattern that matches the reversed
+// hostname labels, or nil if none match.
+func (t *domainTrie) Match(target []string) *domainName {
+	m := domainMatch{
+		target: target,
+		order:  t.count,
+	}
+	m.walk(t.root, 0)
+	return m.best
+}
+
+// domainMatch is the state of a single lookup.
+type domainMatch struct {
+	target []string
+	best   *domainName
+	order  int
+}
+
+func (m *domainMatch) found(d *domainName, order int) {
+	if d != nil && order < m.order {
+		m.best, m.order = d, order
+	}
+}
+
+func (m *domainMatch) walk(n *domainNode, i int) {
+	m.found(n.suffix, n.suffixOrder)
+	if i == len(m.target) {
+		m.found(n.exact, n.exactOrder)
+	}
+
+	if n.globstar != nil {
+		// '**' consumes any number of labels, including none.
+		for j := i; j <= len(m.target); j++ {
+			m.walk(n.globstar, j)
+		}
+	}
+
+	if i == len(m.target) {
+		return
+	}
+
+	if c, ok := n.children[m.target[i]]; ok {
+		m.walk(c, i+1)
+	}
+	if n.star != nil {
+		m.walk(n.star, i+1)
+	}
+}
@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// linearMatch is the reference implementation the trie must agree with.
+func linearMatch(patterns []*domainName, target []string) *domainName {
+	for _, p := range patterns {
+		if p.Match(target) {
+			return p
+		}
+	}
+	return nil
+}
+
+func Test_domainTrie(t *testing.T) {
+	patterns := []string{
+		"example.com",
+		"*.example.com",
+		"=example.com",
+		"=*.example.com",
+		"www.example.com",
+		"example.*",
+		"*",
+		"*.*",
+		"**",
+		"=**",
+		"api.**.example.com",
+		"=api.**.example.com",
+		"**.example.com",
+		"*.**.com",
+		"=*.**.*",
+		"org",
+		"=org",
+		"a.**.b.**.c",
+	}
+
+	hosts := []string{
+		"com",
+		"org",
+		"example.com",
+		"example.org",
+		"www.example.com",
+		"api.example.com",
+		"api.v1.example.com",
+		"x.api.v1.example.com",
+		"a.b.c",
+		"a.x.b.y.c",
+		"a.c",
+		"b.a.c",
+	}
+
+	// Every window of the patterns is compiled so the ordering of the
+	// patterns is also covered.
+	for start := range patterns {
+		for end := start + 1; end <= len(patterns); end++ {
+			subset := patterns[start:end]
+
+			trie := newDomainTrie()
+			list := make([]*domainName, 0, len(subset))
+			for _, p := range subset {
+				d, err := newDomainName(p)
+				require.NoError(t, err)
+				trie.Add(d)
+				list = append(list, d)
+			}
+
+			for _, host := range hosts {
+				target, err := hostnameProcess(host)
+				require.NoError(t, err)
+
+				assert.Equal(t, linearMatch(list, target), trie.Match(target),
+					"patterns: %v host: %s", subset, host)
+			}
+		}
+	}
+}
+
+func Test_domainTrieLarge(t *testing.T) {
+	domains := make([]string, 0, 100000)
+	for i := 0; i < cap(domains); i++ {
+		domains = append(domains, fmt.Sprintf("bad-%d.example.com", i))
+	}
+
+	list, err := newDomainList(domains)
+	require.NoError(t, err)
+
+	target, err := hostnameProcess("www.bad-99999.example.com")
+	require.NoError(t, err)
+	got := list.Match(target)
+	require.NotNil(t, got)
+	assert.Equal(t, "bad-99999.example.com", got.original)
+
+	target, err = hostnameProcess("www.good.example.com")
+	require.NoError(t, err)
+	assert.Nil(t, list.Match(target))
+}
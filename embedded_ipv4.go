@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"net/netip"
+)
+
+// CheckEmbeddedIPv4 returns an Option that extracts the IPv4 addresses
+// embedded in IPv6 addresses and runs every IP rule that forbids addresses
+// against them as well as the IPv6 address.  This prevents an address such
+// as 64:ff9b::a00:1 from carrying a forbidden IPv4 address past IPv4 subnet
+// rules.
+//
+// The following forms are supported:
+//
+//   - IPv4-compatible, ::a.b.c.d (RFC 4291)
+//   - NAT64, 64:ff9b::/96 and 64:ff9b:1::/48 (RFC 6052, RFC 8215)
+//   - 6to4, 2002::/16 (RFC 3056)
+//   - Teredo, 2001::/32, both the server and the client address (RFC 4380)
+//
+// IPv4-mapped addresses (::ffff:a.b.c.d) are always checked as IPv4
+// addresses.
+//
+// Rules that only allow addresses, such as OnlyAllowSubnets, check the IPv6
+// address alone, so allowing 2002::/16 allows every 6to4 address regardless
+// of the IPv4 address it carries.  Custom IP vadors are treated as rules
+// that forbid addresses.
+func CheckEmbeddedIPv4() Option {
+	return checkEmbeddedIPv4Option{}
+}
+
+type checkEmbeddedIPv4Option struct{}
+
+func (checkEmbeddedIPv4Option) String() string {
+	return "CheckEmbeddedIPv4()"
+}
+
+func (checkEmbeddedIPv4Option) apply(c *Checker) {
+	c.embeddedIPv4 = true
+}
+
+var (
+	nat64Prefix      = netip.MustParsePrefix("64:ff9b::/96")
+	nat64LocalPrefix = netip.MustParsePrefix("64:ff9b:1::/48")
+	sixToFourPrefix  = netip.MustParsePrefix("2002::/16")
+	teredoPrefix     = netip.MustParsePrefix("2001::/32")
+	compatPrefix     = netip.MustParsePrefix("::/96")
+)
+
+// embeddedIPv4 returns the IPv4 addresses embedded in the IPv6 address.
+func embeddedIPv4(addr netip.Addr) []netip.Addr {
+	if !addr.Is6() || addr.Is4In6() {
+		return nil
+	}
+
+	ip := addr.As16()
+	switch {
+	case compatPrefix.Contains(addr):
+		// IPv4-compatible, but not :: or ::1.
+		if ip[12] == 0 && ip[13] == 0 && ip[14] == 0 && ip[15] <= 1 {
+			return nil
+		}
+		return []netip.Addr{netip.AddrFrom4([4]byte{ip[12], ip[13], ip[14], ip[15]})}
+	case nat64Prefix.Contains(addr):
+		return []netip.Addr{netip.AddrFrom4([4]byte{ip[12], ip[13], ip[14], ip[15]})}
+	case nat64LocalPrefix.Contains(addr):
+		// A /48 prefix places the address around the reserved 'u' octet.
+		return []netip.Addr{netip.AddrFrom4([4]byte{ip[6], ip[7], ip[9], ip[10]})}
+	case sixToFourPrefix.Contains(addr):
+		return []netip.Addr{netip.AddrFrom4([4]byte{ip[2], ip[3], ip[4], ip[5]})}
+	case teredoPrefix.Contains(addr):
+		return []netip.Addr{
+			netip.AddrFrom4([4]byte{ip[4], ip[5], ip[6], ip[7]}),
+			netip.AddrFrom4([4]byte{^ip[12], ^ip[13], ^ip[14], ^ip[15]}),
+		}
+	}
+
+	return nil
+}
+
+// checkEmbeddedIPv4 runs the rule against each of the IPv4 addresses
+// embedded in the IP.
+func checkEmbeddedIPv4(rule AddrVador, addr netip.Addr) error {
+	for _, v4 := range embeddedIPv4(addr) {
+		if err := rule(v4); err != nil {
+			return annotate(err, 0, addr.String()+" ("+v4.String()+")", "")
+		}
+	}
+	return nil
+}
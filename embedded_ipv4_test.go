@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckEmbeddedIPv4Option(t *testing.T) {
+	embedded := []string{
+		"http://[::7f00:1]",
+		"http://[64:ff9b::7f00:1]",
+		"http://[64:ff9b:1:7f00:1:1::]",
+		"http://[2002:7f00:1::]",
+		"http://[2001:0:7f00:1::ffff:ffff]",
+		"http://[2001:0:4136:e378:8000:63bf:80ff:fffe]",
+	}
+
+	tests := []sharedTest{
+		{
+			description: "embedded addresses are not checked by default",
+			opt:         ForbidSubnet("127.0.0.0/8"),
+			hosts:       embedded,
+		}, {
+			description: "embedded addresses",
+			opt:         ForbidSubnet("127.0.0.0/8"),
+			opts:        []Option{CheckEmbeddedIPv4()},
+			hosts:       embedded,
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "mapped addresses",
+			opt:         ForbidSubnet("127.0.0.0/8"),
+			host:        "http://[::ffff:7f00:1]",
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "no embedded address",
+			opt:         ForbidSubnet("127.0.0.0/8"),
+			opts:        []Option{CheckEmbeddedIPv4()},
+			hosts: []string{
+				"http://[::]",
+				"http://[2001:db8::7f00:1]",
+				"http://[64:ff9b::808:808]",
+				"http://8.8.8.8",
+			},
+		}, {
+			description: "resolved embedded address",
+			opt:         ForbidLoopback(),
+			opts: []Option{
+				CheckEmbeddedIPv4(),
+				WithResolverContext(mockSliceResolver("2002:7f00:1::")),
+			},
+			host:        "http://example.com",
+			expectedErr: ErrLoopback,
+		}, {
+			description: "allow rules only check the ipv6 address",
+			opt:         OnlyAllowSubnets([]string{"2002::/16"}),
+			opts:        []Option{CheckEmbeddedIPv4()},
+			host:        "http://[2002:a00:1::1]",
+		}, {
+			description: "allow and forbid rules together",
+			opt:         OnlyAllowSubnets([]string{"2002::/16"}),
+			opts:        []Option{CheckEmbeddedIPv4(), ForbidSubnet("10.0.0.0/8")},
+			host:        "http://[2002:a00:1::1]",
+			expectedErr: ErrSubnetNotAllowed,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestCheckEmbeddedIPv4Error(t *testing.T) {
+	err := Must(ForbidSubnet("10.0.0.0/8"), CheckEmbeddedIPv4()).Text("http://[64:ff9b::a00:1]")
+
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, StageIPLiteral, ve.Stage)
+	assert.Equal(t, "64:ff9b::a00:1 (10.0.0.1)", ve.Value)
+	assert.Equal(t, "10.0.0.0/8", ve.Pattern)
+}
+
+func Test_embeddedIPv4(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want []string
+	}{
+		{ip: "10.0.0.1"},
+		{ip: "::"},
+		{ip: "::1"},
+		{ip: "2001:db8::1"},
+		{ip: "::a00:1", want: []string{"10.0.0.1"}},
+		{ip: "64:ff9b::a00:1", want: []string{"10.0.0.1"}},
+		{ip: "64:ff9b:1:a00:0:100::", want: []string{"10.0.0.1"}},
+		{ip: "2002:a00:1::1", want: []string{"10.0.0.1"}},
+		{ip: "2001:0:4136:e378:8000:63bf:3fff:fdd2", want: []string{"65.54.227.120", "192.0.2.45"}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.ip, func(t *testing.T) {
+			got := embeddedIPv4(netip.MustParseAddr(tc.ip))
+
+			strs := make([]string, 0, len(got))
+			for _, ip := range got {
+				strs = append(strs, ip.String())
+			}
+			if tc.want == nil {
+				tc.want = []string{}
+			}
+			assert.Equal(t, tc.want, strs)
+		})
+	}
+}
+
+func TestCheckEmbeddedIPv4OptionString(t *testing.T) {
+	opt := CheckEmbeddedIPv4()
+	assert.Equal(t, "CheckEmbeddedIPv4()", opt.String())
+}
@@ -0,0 +1,51 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+// cloudMetadataIPs are the addresses of the well known cloud instance
+// metadata services.
+var cloudMetadataIPs = []string{
+	"169.254.169.254/32", // AWS, Azure, GCP, OpenStack, etc.
+	"169.254.170.2/32",   // AWS ECS task metadata
+	"fd00:ec2::254/128",  // AWS IPv6
+	"100.100.100.200/32", // Alibaba Cloud
+}
+
+// cloudMetadataHostnames are the hostnames of the well known cloud instance
+// metadata services.
+var cloudMetadataHostnames = []string{
+	"metadata.google.internal",
+	"metadata",
+	"instance-data",
+}
+
+// ForbidCloudMetadata returns an Option that disallows the cloud instance
+// metadata services by both address (169.254.169.254, fd00:ec2::254,
+// 100.100.100.200, etc) and hostname (metadata.google.internal, metadata and
+// instance-data).  The addresses are checked for IP literals and for
+// resolved IPs when a resolver is provided.
+//
+// The errors returned are ErrSubnetNotAllowed and ErrDomainNotAllowed.
+func ForbidCloudMetadata() Option {
+	return forbidCloudMetadataOption{
+		opts: []Option{
+			forbidSubnetsOption("ForbidCloudMetadata", cloudMetadataIPs),
+			forbidDomainNames("ForbidCloudMetadata", cloudMetadataHostnames...),
+		},
+	}
+}
+
+type forbidCloudMetadataOption struct {
+	opts []Option
+}
+
+func (forbidCloudMetadataOption) String() string {
+	return "ForbidCloudMetadata()"
+}
+
+func (o forbidCloudMetadataOption) apply(c *Checker) {
+	for _, opt := range o.opts {
+		opt.apply(c)
+	}
+}
@@ -0,0 +1,63 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForbidCloudMetadataOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "not metadata",
+			opt:         ForbidCloudMetadata(),
+			hosts: []string{
+				"http://169.254.169.253",
+				"http://100.100.100.201",
+				"http://[fd00:ec2::253]",
+				"http://google.internal",
+				"http://metadata.example.com",
+			},
+		}, {
+			description: "metadata addresses",
+			opt:         ForbidCloudMetadata(),
+			hosts: []string{
+				"http://169.254.169.254/latest/meta-data/",
+				"http://169.254.170.2/v2/credentials",
+				"http://[fd00:ec2::254]/latest/meta-data/",
+				"http://100.100.100.200/latest/meta-data/",
+			},
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "metadata hostnames",
+			opt:         ForbidCloudMetadata(),
+			hosts: []string{
+				"http://metadata.google.internal/computeMetadata/v1/",
+				"http://Metadata.Google.Internal./computeMetadata/v1/",
+				"http://metadata/computeMetadata/v1/",
+				"http://instance-data/latest/meta-data/",
+			},
+			expectedErr: ErrDomainNotAllowed,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidCloudMetadataError(t *testing.T) {
+	err := Must(ForbidCloudMetadata()).Text("http://169.254.169.254")
+
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "169.254.169.254/32", ve.Pattern)
+	assert.Equal(t, "ForbidCloudMetadata()", ve.Option)
+}
+
+func TestForbidCloudMetadataOptionString(t *testing.T) {
+	opt := ForbidCloudMetadata()
+	assert.Equal(t, "ForbidCloudMetadata()", opt.String())
+}
@@ -0,0 +1,181 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// ForbidConfusableHosts returns an Option that rejects hostnames that can be
+// visually confused with other hostnames, using checks based on Unicode
+// TR39:
+//
+//   - a label may not mix scripts, such as the Cyrillic 'а' in "аpple.com",
+//     except for the combinations TR39 allows for Chinese, Japanese and
+//     Korean with Latin
+//   - if protected domains are provided, a hostname that has the same
+//     confusable skeleton as a protected domain (or a subdomain of one) is
+//     rejected unless it really is that domain, so "аррӏе.com" is rejected
+//     when "apple.com" is protected
+//
+// The skeletons are built from a partial table of common confusables rather
+// than the complete TR39 data, so this is a heuristic: it catches the usual
+// lookalikes, such as "ɑpple.com", but not every hostname TR39 would find
+// confusable.
+//
+// The errors returned are ErrConfusableHost.  If a protected domain is
+// invalid then the Option will return an error.
+//
+// See https://www.unicode.org/reports/tr39/
+func ForbidConfusableHosts(protected ...string) Option {
+	o := forbidConfusableHostsOption{
+		originals: protected,
+		protected: make([]confusableDomain, 0, len(protected)),
+	}
+
+	for _, domain := range protected {
+		ascii, err := toASCII(strings.TrimSuffix(domain, "."))
+		if err != nil || ascii == "" {
+			return Error(fmt.Errorf("%w: invalid domain '%s'", ErrInvalidInput, domain))
+		}
+		o.protected = append(o.protected, confusableDomain{
+			ascii:    ascii,
+			skeleton: hostSkeleton(ascii),
+		})
+	}
+
+	return o
+}
+
+type forbidConfusableHostsOption struct {
+	originals []string
+	protected []confusableDomain
+}
+
+type confusableDomain struct {
+	ascii    string
+	skeleton string
+}
+
+func (o forbidConfusableHostsOption) String() string {
+	b := strings.Builder{}
+
+	b.WriteString("ForbidConfusableHosts(")
+	comma := ""
+	for _, original := range o.originals {
+		b.WriteString(comma)
+		b.WriteString("'")
+		b.WriteString(original)
+		b.WriteString("'")
+		comma = ", "
+	}
+	b.WriteString(")")
+
+	return b.String()
+}
+
+func (o forbidConfusableHostsOption) apply(c *Checker) {
+	c.hostRules = append(c.hostRules, forbidConfusableHosts(o.protected).withContext())
+}
+
+func forbidConfusableHosts(protected []confusableDomain) HostVador {
+	return func(host string) error {
+		host = strings.TrimSuffix(host, ".")
+		unicodeHost, _ := idnaProfile.ToUnicode(host)
+
+		for _, label := range strings.Split(unicodeHost, ".") {
+			scripts := labelScripts(label)
+			if !allowedScripts(scripts) {
+				return matched(ErrConfusableHost, "mixed scripts: "+strings.Join(scripts, ", "))
+			}
+		}
+
+		skeleton := hostSkeleton(host)
+		for _, p := range protected {
+			if host == p.ascii || strings.HasSuffix(host, "."+p.ascii) {
+				continue
+			}
+			if skeleton == p.skeleton || strings.HasSuffix(skeleton, "."+p.skeleton) {
+				return matched(ErrConfusableHost, p.ascii)
+			}
+		}
+
+		return nil
+	}
+}
+
+// labelScripts returns the sorted names of the scripts used by the label,
+// ignoring the characters shared between scripts (Common and Inherited).
+func labelScripts(label string) []string {
+	found := map[string]bool{}
+	for _, r := range label {
+		if unicode.In(r, unicode.Common, unicode.Inherited) {
+			continue
+		}
+		for name, table := range unicode.Scripts {
+			if unicode.Is(table, r) {
+				found[name] = true
+				break
+			}
+		}
+	}
+
+	rv := make([]string, 0, len(found))
+	for name := range found {
+		rv = append(rv, name)
+	}
+	sort.Strings(rv)
+	return rv
+}
+
+// allowedScriptSets are the combinations of scripts that TR39 allows in a
+// single label at the highly restrictive level.
+var allowedScriptSets = []map[string]bool{
+	{"Latin": true, "Han": true, "Hiragana": true, "Katakana": true},
+	{"Latin": true, "Han": true, "Bopomofo": true},
+	{"Latin": true, "Han": true, "Hangul": true},
+}
+
+// allowedScripts returns true if the scripts may be used together in a label.
+func allowedScripts(scripts []string) bool {
+	if len(scripts) <= 1 {
+		return true
+	}
+
+	for _, set := range allowedScriptSets {
+		ok := true
+		for _, script := range scripts {
+			if !set[script] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hostSkeleton returns the skeleton of the ASCII (punycode) hostname.
+// The skeleton is lowercased since hostnames are case-insensitive.
+func hostSkeleton(host string) string {
+	unicodeHost, _ := idnaProfile.ToUnicode(host)
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(unicodeHost) {
+		if proto, ok := confusables[r]; ok {
+			b.WriteString(proto)
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return strings.ToLower(norm.NFD.String(b.String()))
+}
@@ -0,0 +1,120 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"unicode"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForbidConfusableHostsOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "single script labels",
+			opt:         ForbidConfusableHosts(),
+			hosts: []string{
+				"http://example.com",
+				"http://bücher.de",
+				"http://пример.рф",
+				"http://παράδειγμα.ελ",
+				"http://例え.テスト",
+				"http://日本語テキスト.jp",
+				"http://한국어漢字.kr",
+				"http://cyrillic.пример.com",
+				"http://127.0.0.1",
+			},
+		}, {
+			description: "mixed script labels",
+			opt:         ForbidConfusableHosts(),
+			hosts: []string{
+				"http://аpple.com",
+				"http://xn--pple-43d.com",
+				"http://paypaι.com",
+				"http://www.gооgle.com",
+				"http://한국어テキスト.kr",
+			},
+			expectedErr: ErrConfusableHost,
+		}, {
+			description: "whole script confusables are allowed without protection",
+			opt:         ForbidConfusableHosts(),
+			host:        "http://аррӏе.com",
+		}, {
+			description: "whole script confusables of a protected domain",
+			opt:         ForbidConfusableHosts("apple.com", "microsoft.com"),
+			hosts: []string{
+				"http://аррӏе.com",
+				"http://login.аррӏе.com",
+				"http://app1e.com",
+				"http://rnicrosoft.com",
+			},
+			expectedErr: ErrConfusableHost,
+		}, {
+			description: "single script latin confusables of a protected domain",
+			opt:         ForbidConfusableHosts("apple.com", "cisco.com"),
+			hosts: []string{
+				"http://ɑpple.com",
+				"http://www.ɑpple.com",
+				"http://ᴄɪsco.com",
+			},
+			expectedErr: ErrConfusableHost,
+		}, {
+			description: "protected domains are allowed",
+			opt:         ForbidConfusableHosts("apple.com", "Microsoft.com."),
+			hosts: []string{
+				"http://apple.com",
+				"http://www.apple.com",
+				"http://microsoft.com",
+				"http://example.com",
+				"http://pineapple.com",
+			},
+		}, {
+			description: "invalid protected domain",
+			opt:         ForbidConfusableHosts("xn--a.com"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidConfusableHostsError(t *testing.T) {
+	err := Must(ForbidConfusableHosts()).Text("http://аpple.com")
+
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "mixed scripts: Cyrillic, Latin", ve.Pattern)
+
+	err = Must(ForbidConfusableHosts("apple.com")).Text("http://аррӏе.com")
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "apple.com", ve.Pattern)
+}
+
+func Test_hostSkeleton(t *testing.T) {
+	assert.Equal(t, "apple.corn", hostSkeleton("apple.com"))
+	assert.Equal(t, hostSkeleton("apple.com"), hostSkeleton("xn--80ak6aa92e.com"))
+	assert.Equal(t, hostSkeleton("google.com"), hostSkeleton("g00gle.com"))
+	assert.NotEqual(t, hostSkeleton("apple.com"), hostSkeleton("bücher.de"))
+}
+
+// Test_confusablesLowercase checks that every entry can match, since the
+// skeleton is built from the lowercase form of the hostname.
+func Test_confusablesLowercase(t *testing.T) {
+	for r, proto := range confusables {
+		assert.Equal(t, r, unicode.ToLower(r), string(r))
+		assert.Equal(t, strings.ToLower(proto), proto, string(r))
+	}
+}
+
+func TestForbidConfusableHostsOptionString(t *testing.T) {
+	opt := ForbidConfusableHosts()
+	assert.Equal(t, "ForbidConfusableHosts()", opt.String())
+
+	opt = ForbidConfusableHosts("apple.com", "microsoft.com")
+	assert.Equal(t, "ForbidConfusableHosts('apple.com', 'microsoft.com')", opt.String())
+}
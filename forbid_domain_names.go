@@ -31,28 +31,41 @@ import (
 //   - "foo.www.example.com."
 //   - "*.www.example.com."
 //   - etc...
+//
+// The full pattern grammar is:
+//
+//   - "*" as a whole label matches exactly one label
+//   - "**" as a whole label matches any number of labels, including none, so
+//     "api.**.example.com" matches "api.example.com" and "api.a.b.example.com"
+//   - a leading "=" anchors the pattern so it must match the entire hostname
+//     instead of the end of it, so "=example.com" matches only
+//     "example.com" and "=*.example.com" matches only one level below it
+//   - a leading "!" makes the pattern an exception; a hostname that matches
+//     an exception does not match the list, even if it matches another
+//     pattern.  "!" may be combined with "=", as in "!=www.example.com"
+//
+// Patterns are validated when the Option is built.  Empty labels, '*' mixed
+// with other characters in a label, repeated "**" labels, characters that
+// are not valid in a hostname and patterns without any labels are errors.
 func ForbidDomainNames(domains ...string) Option {
 	return forbidDomainNames("ForbidDomainNames", domains...)
 }
 
 func forbidDomainNames(name string, domains ...string) Option {
-	n := forbidDomainNamesOption{
-		optName: name,
-		domains: make([]*domainName, len(domains)),
+	list, err := newDomainList(domains)
+	if err != nil {
+		return Error(err)
 	}
-	for _, domain := range domains {
-		d, err := newDomainName(domain)
-		if err != nil {
-			return Error(fmt.Errorf("%w: invalid domain '%s'", ErrInvalidInput, domain))
-		}
-		n.domains = append(n.domains, d)
+
+	return &forbidDomainNamesOption{
+		optName: name,
+		domains: list,
 	}
-	return &n
 }
 
 type forbidDomainNamesOption struct {
 	optName string
-	domains []*domainName
+	domains *domainList
 }
 
 func (n forbidDomainNamesOption) String() string {
@@ -61,15 +74,12 @@ func (n forbidDomainNamesOption) String() string {
 	b.WriteString(n.optName)
 	b.WriteString("(")
 	comma := ""
-	for _, d := range n.domains {
-
-		if d != nil {
-			b.WriteString(comma)
-			b.WriteString("'")
-			b.WriteString(d.original)
-			b.WriteString("'")
-			comma = ", "
-		}
+	for _, original := range n.domains.originals {
+		b.WriteString(comma)
+		b.WriteString("'")
+		b.WriteString(original)
+		b.WriteString("'")
+		comma = ", "
 	}
 	b.WriteString(")")
 
@@ -77,44 +87,113 @@ func (n forbidDomainNamesOption) String() string {
 }
 
 func (n forbidDomainNamesOption) apply(c *Checker) {
-	c.hostRules = append(c.hostRules, forbidDomainNamesHostname(n.domains))
+	c.hostRules = append(c.hostRules, forbidDomainNamesHostname(n.domains).withContext())
 }
 
-func forbidDomainNamesHostname(forbid []*domainName) HostVador {
+func forbidDomainNamesHostname(forbid *domainList) HostVador {
 	return func(host string) error {
 		subs, err := hostnameProcess(host)
 		if err != nil {
 			return err
 		}
 
-		for _, forbidden := range forbid {
-			if forbidden == nil {
-				continue
-			}
-			if forbidden.Match(subs) {
-				return ErrDomainNotAllowed
-			}
+		if forbidden := forbid.Match(subs); forbidden != nil {
+			return matched(ErrDomainNotAllowed, forbidden.original)
+		}
+
+		return nil
+	}
+}
+
+// domainList is a list of domain name patterns and exceptions.  The patterns
+// are compiled into tries so the cost of a match is proportional to the
+// number of labels in the hostname instead of the number of patterns.
+type domainList struct {
+	originals  []string
+	patterns   *domainTrie
+	exceptions *domainTrie
+}
+
+func newDomainList(domains []string) (*domainList, error) {
+	list := domainList{
+		originals:  domains,
+		patterns:   newDomainTrie(),
+		exceptions: newDomainTrie(),
+	}
+
+	for _, domain := range domains {
+		d, err := newDomainName(domain)
+		if err != nil {
+			return nil, err
+		}
+
+		if d.exception {
+			list.exceptions.Add(d)
+		} else {
+			list.patterns.Add(d)
 		}
+	}
+
+	return &list, nil
+}
 
+// Match returns the first pattern in the list that matched the reversed
+// hostname labels, or nil if no pattern matched or an exception matched.
+func (l *domainList) Match(target []string) *domainName {
+	if l.exceptions.Match(target) != nil {
 		return nil
 	}
+	return l.patterns.Match(target)
 }
 
 type domainName struct {
-	original string
-	subs     []string
+	original  string
+	subs      []string
+	exact     bool
+	exception bool
 }
 
 func newDomainName(s string) (*domainName, error) {
-	subs, err := hostnameProcess(s)
+	d := domainName{
+		original: s,
+	}
+
+	if strings.HasPrefix(s, "!") {
+		d.exception = true
+		s = s[1:]
+	}
+	if strings.HasPrefix(s, "=") {
+		d.exact = true
+		s = s[1:]
+	}
+
+	if strings.TrimSuffix(s, ".") == "" {
+		return nil, fmt.Errorf("%w: invalid domain '%s' no labels", ErrInvalidInput, d.original)
+	}
+
+	ascii, err := toASCII(s)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: invalid domain '%s' %v", ErrInvalidInput, d.original, err)
 	}
 
-	return &domainName{
-		original: s,
-		subs:     subs,
-	}, nil
+	d.subs, err = hostnameProcess(ascii)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid domain '%s' zero length subdomain", ErrInvalidInput, d.original)
+	}
+
+	for i, sub := range d.subs {
+		if sub != "*" && sub != "**" && strings.Contains(sub, "*") {
+			return nil, fmt.Errorf("%w: invalid domain '%s' '*' must be a whole label", ErrInvalidInput, d.original)
+		}
+		if strings.Trim(sub, "abcdefghijklmnopqrstuvwxyz0123456789-_*") != "" {
+			return nil, fmt.Errorf("%w: invalid domain '%s' invalid character in '%s'", ErrInvalidInput, d.original, sub)
+		}
+		if sub == "**" && i > 0 && d.subs[i-1] == "**" {
+			return nil, fmt.Errorf("%w: invalid domain '%s' repeated '**' labels", ErrInvalidInput, d.original)
+		}
+	}
+
+	return &d, nil
 }
 
 func hostnameProcess(s string) ([]string, error) {
@@ -135,13 +214,29 @@ func hostnameProcess(s string) ([]string, error) {
 	return rv, nil
 }
 
+// Match returns true if the domain name matches the reversed hostname labels.
 func (d *domainName) Match(target []string) bool {
-	// Check for a match in the block list.
-	for i, block := range d.subs {
-		if i >= len(target) || (block != "*" && target[i] != block) {
-			// No match, try the next block.
+	return matchLabels(d.subs, target, d.exact)
+}
+
+// matchLabels matches the reversed pattern labels against the reversed
+// target labels.  Unless exact is true, the pattern only needs to match the
+// start of the target (the end of the hostname).
+func matchLabels(pattern, target []string, exact bool) bool {
+	for i, label := range pattern {
+		if label == "**" {
+			for j := i; j <= len(target); j++ {
+				if matchLabels(pattern[i+1:], target[j:], exact) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if i >= len(target) || (label != "*" && target[i] != label) {
 			return false
 		}
 	}
-	return true
+
+	return !exact || len(pattern) == len(target)
 }
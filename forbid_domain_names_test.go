@@ -32,6 +32,36 @@ func TestForbidDomainNamesOption(t *testing.T) {
 			opt:         ForbidDomainNames("foo..com"),
 			failOnNew:   true,
 			expectedErr: ErrInvalidInput,
+		}, {
+			description: "exceptions",
+			opt:         ForbidDomainNames("example.com", "!=www.example.com", "!api.example.com"),
+			hosts: []string{
+				"http://www.example.com",
+				"http://api.example.com",
+				"http://v1.api.example.com",
+				"http://example.org",
+			},
+		}, {
+			description: "exceptions do not cover the rest",
+			opt:         ForbidDomainNames("example.com", "!=www.example.com", "!api.example.com"),
+			hosts: []string{
+				"http://example.com",
+				"http://a.www.example.com",
+				"http://mail.example.com",
+			},
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "any depth under a domain, but not the domain",
+			opt:         ForbidDomainNames("*.**.example.com"),
+			hosts: []string{
+				"http://www.example.com",
+				"http://a.b.c.example.com",
+			},
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "the domain itself is not matched",
+			opt:         ForbidDomainNames("*.**.example.com"),
+			host:        "http://example.com",
 		}, {
 			description: "happy path",
 			opt:         ForbidDomainNames(),
@@ -139,6 +169,54 @@ func Test_domainName_Match(t *testing.T) {
 			input:  "*.*.*",
 			target: "example.com",
 			match:  false,
+		}, {
+			input:  "**",
+			target: "example.com",
+			match:  true,
+		}, {
+			input:  "**.example.com",
+			target: "example.com",
+			match:  true,
+		}, {
+			input:  "api.**.example.com",
+			target: "api.example.com",
+			match:  true,
+		}, {
+			input:  "api.**.example.com",
+			target: "api.a.b.example.com",
+			match:  true,
+		}, {
+			input:  "api.**.example.com",
+			target: "www.a.b.example.com",
+			match:  false,
+		}, {
+			input:  "api.**.*.com",
+			target: "api.example.com",
+			match:  true,
+		}, {
+			input:  "=example.com",
+			target: "example.com",
+			match:  true,
+		}, {
+			input:  "=example.com",
+			target: "www.example.com",
+			match:  false,
+		}, {
+			input:  "=*.example.com",
+			target: "www.example.com",
+			match:  true,
+		}, {
+			input:  "=*.example.com",
+			target: "a.www.example.com",
+			match:  false,
+		}, {
+			input:  "=**.example.com",
+			target: "a.www.example.com",
+			match:  true,
+		}, {
+			input:  "=api.**.example.com",
+			target: "v1.api.a.example.com",
+			match:  false,
 		},
 	}
 	for _, tc := range tests {
@@ -161,6 +239,58 @@ func Test_domainName_Match(t *testing.T) {
 	}
 }
 
+func Test_newDomainNameGrammar(t *testing.T) {
+	tests := []struct {
+		s           string
+		want        domainName
+		expectedErr error
+	}{
+		{
+			s:    "=example.com",
+			want: domainName{original: "=example.com", subs: []string{"com", "example"}, exact: true},
+		}, {
+			s:    "!example.com",
+			want: domainName{original: "!example.com", subs: []string{"com", "example"}, exception: true},
+		}, {
+			s:    "!=*.**.example.com.",
+			want: domainName{original: "!=*.**.example.com.", subs: []string{"com", "example", "**", "*"}, exact: true, exception: true},
+		}, {
+			s:           "",
+			expectedErr: ErrInvalidInput,
+		}, {
+			s:           "!=",
+			expectedErr: ErrInvalidInput,
+		}, {
+			s:           "=!example.com",
+			expectedErr: ErrInvalidInput,
+		}, {
+			s:           "ex*.com",
+			expectedErr: ErrInvalidInput,
+		}, {
+			s:           "***.com",
+			expectedErr: ErrInvalidInput,
+		}, {
+			s:           "**.**.com",
+			expectedErr: ErrInvalidInput,
+		}, {
+			s:           "example..com",
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.s, func(t *testing.T) {
+			assert := assert.New(t)
+
+			got, err := newDomainName(tc.s)
+
+			assert.ErrorIs(err, tc.expectedErr)
+			if tc.expectedErr == nil {
+				assert.Equal(&tc.want, got)
+			}
+		})
+	}
+}
+
 func TestForbidDomainNamesOptionString(t *testing.T) {
 	opt := ForbidDomainNames()
 	assert.Equal(t, "ForbidDomainNames()", opt.String())
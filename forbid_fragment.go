@@ -0,0 +1,28 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+// ForbidFragment returns an Option that disallows URLs with a fragment.  A
+// fragment is never sent to the server, so a URL that needs one is usually
+// meant for a browser rather than a server-side request.
+func ForbidFragment() Option {
+	return forbidFragmentOption{}
+}
+
+type forbidFragmentOption struct{}
+
+func (forbidFragmentOption) String() string {
+	return "ForbidFragment()"
+}
+
+func (forbidFragmentOption) apply(c *Checker) {
+	c.fragmentRules = append(c.fragmentRules, forbidFragment)
+}
+
+func forbidFragment(fragment string) error {
+	if fragment != "" {
+		return ErrFragmentNotAllowed
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForbidFragmentOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "no fragment",
+			opt:         ForbidFragment(),
+			host:        "http://example.com/path",
+		}, {
+			description: "fragment",
+			opt:         ForbidFragment(),
+			host:        "http://example.com/path#section",
+			expectedErr: ErrFragmentNotAllowed,
+		}, {
+			description: "encoded fragment",
+			opt:         ForbidFragment(),
+			host:        "http://example.com/#%2Fadmin",
+			expectedErr: ErrFragmentNotAllowed,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidFragmentError(t *testing.T) {
+	c, err := New(ForbidFragment())
+	require.NoError(t, err)
+
+	err = c.Text("http://example.com/#access_token=secret")
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, StageFragment, ve.Stage)
+	assert.Empty(t, ve.Value)
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestForbidFragmentOptionString(t *testing.T) {
+	opt := ForbidFragment()
+	assert.Equal(t, "ForbidFragment()", opt.String())
+}
@@ -4,7 +4,7 @@
 package urlegit
 
 import (
-	"net"
+	"net/netip"
 )
 
 // ForbidAnyIPs returns an Option that disallows any IP addresses using only
@@ -25,6 +25,6 @@ func (forbidAnyIPsOption) apply(c *Checker) {
 	c.ipBeforeRules = append(c.ipBeforeRules, forbidIPs)
 }
 
-func forbidIPs(*net.IP) error {
+func forbidIPs(netip.Addr) error {
 	return ErrIPNotAllowed
 }
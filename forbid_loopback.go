@@ -4,7 +4,7 @@
 package urlegit
 
 import (
-	"net"
+	"net/netip"
 )
 
 // ForbidLoopback returns an Option that disallows loopback addresses using only
@@ -21,11 +21,11 @@ func (forbidLoopbackOption) String() string {
 
 func (forbidLoopbackOption) apply(c *Checker) {
 	c.ipRules = append(c.ipRules, forbidLoopbackIP)
-	c.hostRules = append(c.hostRules, forbidLoopbackHostname)
+	c.hostRules = append(c.hostRules, HostVador(forbidLoopbackHostname).withContext())
 }
 
-func forbidLoopbackIP(ip *net.IP) error {
-	if ip.IsLoopback() {
+func forbidLoopbackIP(addr netip.Addr) error {
+	if addr.IsLoopback() {
 		return ErrLoopback
 	}
 	return nil
@@ -33,7 +33,7 @@ func forbidLoopbackIP(ip *net.IP) error {
 
 func forbidLoopbackHostname(host string) error {
 	if host == "localhost" {
-		return ErrLoopback
+		return matched(ErrLoopback, host)
 	}
 	return nil
 }
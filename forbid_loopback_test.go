@@ -14,6 +14,8 @@ func TestForbidLoopbackOption(t *testing.T) {
 		"http://127.0.0.1",
 		"http://[::1]",
 		"http://localhost",
+		"http://localhost.",
+		"http://LOCALHOST.:8080",
 	}
 
 	tests := []sharedTest{
@@ -0,0 +1,36 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+// privateNetworks are the address ranges that are only reachable inside of a
+// private or local network.
+var privateNetworks = []string{
+	"10.0.0.0/8",     // RFC 1918
+	"172.16.0.0/12",  // RFC 1918
+	"192.168.0.0/16", // RFC 1918
+	"100.64.0.0/10",  // RFC 6598, carrier-grade NAT
+	"169.254.0.0/16", // RFC 3927, link-local
+	"fc00::/7",       // RFC 4193, unique local
+	"fe80::/10",      // RFC 4291, link-local
+	"fec0::/10",      // RFC 3879, deprecated site-local
+}
+
+// ForbidPrivateNetworks returns an Option that disallows addresses in the
+// private network ranges (RFC 1918), carrier-grade NAT (RFC 6598), unique
+// local (RFC 4193) and link-local ranges.  The rule applies to IP literals
+// and to resolved IPs when a resolver is provided.  The error returned is
+// ErrSubnetNotAllowed.
+func ForbidPrivateNetworks() Option {
+	return forbidPrivateNetworksOption{
+		Option: forbidSubnetsOption("ForbidPrivateNetworks", privateNetworks),
+	}
+}
+
+type forbidPrivateNetworksOption struct {
+	Option
+}
+
+func (forbidPrivateNetworksOption) String() string {
+	return "ForbidPrivateNetworks()"
+}
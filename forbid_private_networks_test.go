@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForbidPrivateNetworksOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "public addresses",
+			opt:         ForbidPrivateNetworks(),
+			hosts: []string{
+				"http://8.8.8.8",
+				"http://100.128.0.1",
+				"http://172.32.0.1",
+				"http://[2001:4860:4860::8888]",
+				"http://example.com",
+			},
+		}, {
+			description: "private addresses",
+			opt:         ForbidPrivateNetworks(),
+			hosts: []string{
+				"http://10.1.2.3",
+				"http://172.16.0.1",
+				"http://172.31.255.255",
+				"http://192.168.1.1",
+				"http://100.64.0.1",
+				"http://169.254.169.254",
+				"http://[fd00::1]",
+				"http://[fe80::1]",
+			},
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "resolved private address",
+			opt:         ForbidPrivateNetworks(),
+			opts:        []Option{WithResolver(mockResolver)},
+			host:        mockLoopbackPrivateURL,
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "resolved public address",
+			opt:         ForbidPrivateNetworks(),
+			opts:        []Option{WithResolver(mockResolver)},
+			host:        mockLoopbackURL,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidPrivateNetworksError(t *testing.T) {
+	err := Must(ForbidPrivateNetworks()).Text("http://172.20.0.1")
+
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "172.16.0.0/12", ve.Pattern)
+	assert.Equal(t, "ForbidPrivateNetworks()", ve.Option)
+}
+
+func TestForbidPrivateNetworksOptionString(t *testing.T) {
+	opt := ForbidPrivateNetworks()
+	assert.Equal(t, "ForbidPrivateNetworks()", opt.String())
+}
@@ -4,8 +4,9 @@
 package urlegit
 
 import (
+	"context"
 	"fmt"
-	"net"
+	"net/netip"
 	"strings"
 )
 
@@ -15,26 +16,49 @@ import (
 // and check the returned IP addresses for loopback addresses.  If the subnet is
 // invalid then the Option will return an error.
 func ForbidSubnet(subnet string, resolver ...Resolver) Option {
-	return forbidSubnetsOption("ForbidSubnet", []string{subnet}, resolver...)
+	return forbidSubnetsOption("ForbidSubnet", []string{subnet}, contextResolvers(resolver)...)
 }
 
+// ForbidSubnets is the same as ForbidSubnet, but for a list of subnets.
+// Overlapping and adjacent subnets are aggregated when the Option is built,
+// so a check costs the same for a handful of subnets or tens of thousands of
+// them.  An error still reports the configured subnets that matched, so
+// 10.0.0.129 is reported as '10.0.0.128/25' even when '10.0.0.0/25' is also
+// provided and the two are checked as '10.0.0.0/24'.
 func ForbidSubnets(subnets []string, resolver ...Resolver) Option {
+	return forbidSubnetsOption("ForbidSubnets", subnets, contextResolvers(resolver)...)
+}
+
+// ForbidSubnetContext is the same as ForbidSubnet, but the optional resolver
+// is a ResolverContext.
+func ForbidSubnetContext(subnet string, resolver ...ResolverContext) Option {
+	return forbidSubnetsOption("ForbidSubnet", []string{subnet}, resolver...)
+}
+
+// ForbidSubnetsContext is the same as ForbidSubnets, but the optional resolver
+// is a ResolverContext.
+func ForbidSubnetsContext(subnets []string, resolver ...ResolverContext) Option {
 	return forbidSubnetsOption("ForbidSubnets", subnets, resolver...)
 }
 
-func forbidSubnetsOption(name string, subnets []string, resolver ...Resolver) Option {
+func contextResolvers(resolvers []Resolver) []ResolverContext {
+	rv := make([]ResolverContext, 0, len(resolvers))
+	for _, r := range resolvers {
+		rv = append(rv, r.withContext())
+	}
+	return rv
+}
+
+func forbidSubnetsOption(name string, subnets []string, resolver ...ResolverContext) Option {
 	f := forbidSubnetOption{
-		optName:   "ForbidSubnet",
-		subnets:   make([]*net.IPNet, 0, len(subnets)),
+		optName:   name,
 		originals: subnets,
 	}
 
-	for _, subnet := range subnets {
-		_, cidr, err := net.ParseCIDR(subnet)
-		if err != nil {
-			return Error(fmt.Errorf("%w: invalid subnet '%s'", ErrInvalidInput, subnet))
-		}
-		f.subnets = append(f.subnets, cidr)
+	var err error
+	f.subnets, err = parsePrefixSet(subnets)
+	if err != nil {
+		return Error(err)
 	}
 
 	switch len(resolver) {
@@ -51,8 +75,8 @@ func forbidSubnetsOption(name string, subnets []string, resolver ...Resolver) Op
 type forbidSubnetOption struct {
 	optName   string
 	originals []string
-	subnets   []*net.IPNet
-	r         Resolver
+	subnets   *prefixSet
+	r         ResolverContext
 }
 
 func (n forbidSubnetOption) String() string {
@@ -84,28 +108,29 @@ func (n forbidSubnetOption) apply(c *Checker) {
 	}
 }
 
-func forbidSubnets(subnets []*net.IPNet) IPVador {
-	return func(ip *net.IP) error {
-		for _, subnet := range subnets {
-			if subnet.Contains(*ip) {
-				return ErrSubnetNotAllowed
-			}
+func forbidSubnets(subnets *prefixSet) AddrVador {
+	return func(addr netip.Addr) error {
+		if subnets.Contains(addr) {
+			return matched(ErrSubnetNotAllowed, subnets.Matching(addr))
 		}
 		return nil
 	}
 }
 
-func forbidSubnetsUser(subnets []*net.IPNet, fn Resolver) HostVador {
-	return func(host string) error {
-		ips, err := fn(host)
+func forbidSubnetsUser(subnets *prefixSet, fn ResolverContext) hostRule {
+	return func(ctx context.Context, host string) error {
+		ips, err := fn(ctx, host)
 		if err != nil {
 			return err
 		}
 
-		for _, ip := range ips {
-			for _, subnet := range subnets {
-				if subnet.Contains(ip) {
-					return ErrSubnetNotAllowed
+		for _, addr := range addrsFromIPs(ips) {
+			if subnets.Contains(addr) {
+				return &ValidationError{
+					Stage:   StageResolvedIP,
+					Value:   addr.String(),
+					Pattern: subnets.Matching(addr),
+					Err:     ErrSubnetNotAllowed,
 				}
 			}
 		}
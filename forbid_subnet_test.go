@@ -4,9 +4,11 @@
 package urlegit
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestForbidSubnetOption(t *testing.T) {
@@ -49,12 +51,58 @@ func TestForbidSubnetOption(t *testing.T) {
 	testCommon(t, tests)
 }
 
+func TestForbidSubnetContextOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "forbid subnet with resolver, no match",
+			opt:         ForbidSubnetContext("10.0.0.0/8", mockResolverContext),
+			host:        mockPrivateURL,
+		}, {
+			description: "forbid subnet with resolver, disallowed subnet",
+			opt:         ForbidSubnetContext("192.168.1.0/8", mockResolverContext),
+			host:        mockPrivateURL,
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "forbid subnets with resolver, disallowed subnet",
+			opt:         ForbidSubnetsContext([]string{"10.0.0.0/8", "192.168.1.0/24"}, mockResolverContext),
+			host:        mockPrivateURL,
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "too many resolvers",
+			opt:         ForbidSubnetContext("10.0.0.0/8", mockResolverContext, mockResolverContext),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
 func TestForbidSubnetsOption(t *testing.T) {
 	tests := []sharedTest{
 		{
 			description: "forbid subnet",
 			opt:         ForbidSubnets([]string{"10.0.0.0/8", "10.0.0.0/24"}),
 			host:        "http://192.168.1.1",
+		}, {
+			description: "forbid subnet, adjacent subnets",
+			opt:         ForbidSubnets([]string{"10.0.0.128/25", "10.0.0.0/25"}),
+			host:        "http://10.0.0.129",
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "forbid subnet, ipv6",
+			opt:         ForbidSubnets([]string{"10.0.0.0/8", "fd00::/8"}),
+			host:        "http://[fd00::1]",
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "forbid subnet, ipv6 with a zone",
+			opt:         ForbidSubnets([]string{"fe80::/10"}),
+			host:        "http://[fe80::1%25eth0]",
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "forbid subnet, invalid",
+			opt:         ForbidSubnets([]string{"10.0.0.0/8", "10.0.0.0/33"}),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
 		},
 	}
 	testCommon(t, tests)
@@ -68,8 +116,39 @@ func TestForbidSubnetOptionString(t *testing.T) {
 	assert.Equal(t, "ForbidSubnet('10.0.0.0/8', resolver)", opt.String())
 
 	opt = ForbidSubnets([]string{"10.0.0.0/8", "10.0.0.0/24"})
-	assert.Equal(t, "ForbidSubnet('10.0.0.0/8', '10.0.0.0/24')", opt.String())
+	assert.Equal(t, "ForbidSubnets('10.0.0.0/8', '10.0.0.0/24')", opt.String())
 
 	opt = ForbidSubnets([]string{"10.0.0.0/8", "10.0.0.0/24"}, mockResolver)
-	assert.Equal(t, "ForbidSubnet('10.0.0.0/8', '10.0.0.0/24', resolver)", opt.String())
+	assert.Equal(t, "ForbidSubnets('10.0.0.0/8', '10.0.0.0/24', resolver)", opt.String())
+}
+
+func TestForbidSubnetsErrorOption(t *testing.T) {
+	c, err := New(ForbidSubnets([]string{"10.0.0.0/8"}))
+	require.NoError(t, err)
+
+	err = c.Text("http://10.1.1.1")
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "ForbidSubnets('10.0.0.0/8')", ve.Option)
+
+	c, err = New(ForbidSubnetsContext([]string{"10.0.0.0/8"}))
+	require.NoError(t, err)
+
+	err = c.Text("http://10.1.1.1")
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "ForbidSubnets('10.0.0.0/8')", ve.Option)
+}
+
+func TestForbidSubnetsErrorPattern(t *testing.T) {
+	c, err := New(ForbidSubnets([]string{"10.0.0.128/25", "10.0.0.0/25", "10.1.2.3/8"}))
+	require.NoError(t, err)
+
+	err = c.Text("http://10.0.0.129")
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "10.1.2.3/8, 10.0.0.128/25", ve.Pattern)
+
+	err = c.Text("http://10.9.9.9")
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "10.1.2.3/8", ve.Pattern)
 }
@@ -0,0 +1,57 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// idnaProfile applies the UTS #46 mapping and IDNA conversion the same way
+// the WHATWG URL specification does.  The STD3 rules are not applied so '*'
+// and '_' remain valid in domain patterns and hostnames.
+var idnaProfile = idna.New(
+	idna.MapForLookup(),
+	idna.BidiRule(),
+	idna.CheckJoiners(true),
+	idna.CheckHyphens(false),
+	idna.StrictDomainName(false),
+	idna.Transitional(false),
+)
+
+// ForbidInvalidIDN returns an Option that rejects hostnames that are not
+// valid internationalized domain names, for example an 'xn--' label that is
+// not valid punycode or a label that mixes bidirectional text incorrectly.
+//
+// Hostnames are always converted to their canonical ASCII form using UTS #46
+// mapping and IDNA ToASCII conversion before the host rules are applied, so
+// "ＥＸＡＭＰＬＥ。com" is checked as "example.com" and "bücher.de" is checked
+// as "xn--bcher-kva.de".  A trailing root dot is removed, so "localhost." is
+// checked as "localhost".  Without this option, a hostname that fails
+// conversion is checked in the best form the conversion could produce.
+func ForbidInvalidIDN() Option {
+	return forbidInvalidIDNOption{}
+}
+
+type forbidInvalidIDNOption struct{}
+
+func (forbidInvalidIDNOption) String() string {
+	return "ForbidInvalidIDN()"
+}
+
+func (o forbidInvalidIDNOption) apply(c *Checker) {
+	c.invalidIDN = o
+}
+
+// toASCII converts the hostname into its canonical ASCII form.  If the
+// hostname is not a valid IDN, the best effort conversion is returned along
+// with the error.
+func toASCII(host string) (string, error) {
+	ascii, err := idnaProfile.ToASCII(host)
+	if ascii == "" {
+		ascii = host
+	}
+	return strings.ToLower(ascii), err
+}
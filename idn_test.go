@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDN(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "unicode variants of a forbidden domain",
+			opt:         ForbidDomainNames("example.com"),
+			hosts: []string{
+				"http://example。com",
+				"http://ｅｘａｍｐｌｅ．ｃｏｍ",
+				"http://EXAMPLE.COM",
+			},
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "unicode pattern matches the punycode host",
+			opt:         ForbidDomainNames("bücher.de"),
+			hosts: []string{
+				"http://xn--bcher-kva.de",
+				"http://BÜCHER.de",
+				"http://shop.bücher.de",
+			},
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "punycode pattern matches the unicode host",
+			opt:         ForbidDomainNames("xn--bcher-kva.de"),
+			host:        "http://bücher.de",
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "fullwidth digits are an IP literal",
+			opt:         ForbidLoopback(),
+			host:        "http://１２７.０.０.１",
+			expectedErr: ErrLoopback,
+		}, {
+			description: "invalid IDNs are allowed by default",
+			opt:         ForbidDomainNames("example.com"),
+			hosts: []string{
+				"http://xn--a.com",
+				"http://a‍b.com",
+			},
+		}, {
+			description: "invalid IDNs are forbidden",
+			opt:         ForbidInvalidIDN(),
+			hosts: []string{
+				"http://xn--a.com",
+				"http://a‍b.com",
+			},
+			expectedErr: ErrInvalidIDN,
+		}, {
+			description: "valid hosts with the option",
+			opt:         ForbidInvalidIDN(),
+			hosts: []string{
+				"http://example.com",
+				"http://bücher.de",
+				"http://_service.example.com",
+				"http://127.0.0.1",
+				"http://[::1]",
+			},
+		}, {
+			description: "invalid pattern",
+			opt:         ForbidDomainNames("xn--a.com"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestIDNWHATWG(t *testing.T) {
+	u, err := WHATWG("http://BÜCHER。de/")
+	assert.NoError(t, err)
+	assert.Equal(t, "xn--bcher-kva.de", u.Hostname())
+
+	_, err = WHATWG("http://xn--a.com/")
+	assert.ErrorIs(t, err, ErrInvalidIDN)
+}
+
+func TestForbidInvalidIDNOptionString(t *testing.T) {
+	opt := ForbidInvalidIDN()
+	assert.Equal(t, "ForbidInvalidIDN()", opt.String())
+}
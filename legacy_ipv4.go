@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+)
+
+// ForbidLegacyIPv4 returns an Option that rejects hosts written in one of the
+// legacy IPv4 notations (decimal 2130706433, hex 0x7f.1, octal 0177.0.0.1 or
+// short 127.1) instead of checking them as IP literals.
+//
+// By default these hosts are parsed the way the WHATWG URL specification
+// parses them, since that is how browsers and many HTTP stacks treat them,
+// and then every IP rule is applied to the resulting address.  As in the
+// specification, a host that ends in a number but is not a valid IPv4
+// address is rejected with ErrInvalidInput.
+func ForbidLegacyIPv4() Option {
+	return forbidLegacyIPv4Option{}
+}
+
+type forbidLegacyIPv4Option struct{}
+
+func (forbidLegacyIPv4Option) String() string {
+	return "ForbidLegacyIPv4()"
+}
+
+func (o forbidLegacyIPv4Option) apply(c *Checker) {
+	c.legacyIPv4 = o
+}
+
+// parseHostIP parses the host as an IP address.  Hosts that end in a number
+// are parsed using the WHATWG IPv4 parser, and legacy is true if the host is
+// not in the standard dotted-decimal form.  If the host ends in a number but
+// is not a valid IPv4 address, an error is returned.  If the host is not an
+// IP address, the zero netip.Addr is returned.  IPv4-mapped IPv6 addresses
+// are returned as IPv4 addresses.
+func parseHostIP(host string) (ip netip.Addr, legacy bool, err error) {
+	if ip, err = netip.ParseAddr(host); err == nil {
+		return ip.Unmap(), false, nil
+	}
+	if !endsInANumber(host) {
+		return netip.Addr{}, false, nil
+	}
+
+	ip, err = parseWHATWGIPv4(host)
+	if err != nil {
+		return netip.Addr{}, false, err
+	}
+
+	// A trailing dot alone does not make the notation legacy.
+	_, err = netip.ParseAddr(strings.TrimSuffix(host, "."))
+	legacy = err != nil
+
+	return ip, legacy, nil
+}
+
+// endsInANumber implements the WHATWG "ends in a number checker".
+//
+// See https://url.spec.whatwg.org/#ends-in-a-number-checker
+func endsInANumber(host string) bool {
+	parts := strings.Split(host, ".")
+	if parts[len(parts)-1] == "" {
+		if len(parts) == 1 {
+			return false
+		}
+		parts = parts[:len(parts)-1]
+	}
+
+	last := parts[len(parts)-1]
+	if last != "" && strings.Trim(last, "0123456789") == "" {
+		return true
+	}
+
+	_, err := parseIPv4Number(last)
+	return err == nil
+}
+
+// parseWHATWGIPv4 implements the WHATWG IPv4 parser.
+//
+// See https://url.spec.whatwg.org/#concept-ipv4-parser
+func parseWHATWGIPv4(host string) (netip.Addr, error) {
+	parts := strings.Split(host, ".")
+	if parts[len(parts)-1] == "" && len(parts) > 1 {
+		parts = parts[:len(parts)-1]
+	}
+
+	if len(parts) > 4 {
+		return netip.Addr{}, fmt.Errorf("%w: invalid IPv4 address '%s'", ErrInvalidInput, host)
+	}
+
+	numbers := make([]uint64, 0, len(parts))
+	for _, part := range parts {
+		n, err := parseIPv4Number(part)
+		if err != nil {
+			return netip.Addr{}, fmt.Errorf("%w: invalid IPv4 address '%s'", ErrInvalidInput, host)
+		}
+		numbers = append(numbers, n)
+	}
+
+	last := numbers[len(numbers)-1]
+	for _, n := range numbers[:len(numbers)-1] {
+		if n > 255 {
+			return netip.Addr{}, fmt.Errorf("%w: invalid IPv4 address '%s'", ErrInvalidInput, host)
+		}
+	}
+	if last >= 1<<(8*(5-len(numbers))) {
+		return netip.Addr{}, fmt.Errorf("%w: invalid IPv4 address '%s'", ErrInvalidInput, host)
+	}
+
+	ipv4 := last
+	for i, n := range numbers[:len(numbers)-1] {
+		ipv4 += n << (8 * (3 - i))
+	}
+
+	return netip.AddrFrom4([4]byte{byte(ipv4 >> 24), byte(ipv4 >> 16), byte(ipv4 >> 8), byte(ipv4)}), nil
+}
+
+// parseIPv4Number implements the WHATWG IPv4 number parser.
+//
+// See https://url.spec.whatwg.org/#ipv4-number-parser
+func parseIPv4Number(s string) (uint64, error) {
+	if s == "" {
+		return 0, ErrInvalidInput
+	}
+
+	base := 10
+	switch {
+	case strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X"):
+		base = 16
+		s = s[2:]
+	case len(s) > 1 && s[0] == '0':
+		base = 8
+		s = s[1:]
+	}
+
+	if s == "" {
+		return 0, nil
+	}
+
+	// Only digits of the base are allowed; ParseUint also accepts
+	// underscores in some forms.
+	const digits = "0123456789abcdef"
+	for _, r := range strings.ToLower(s) {
+		i := strings.IndexRune(digits, r)
+		if i < 0 || i >= base {
+			return 0, ErrInvalidInput
+		}
+	}
+
+	n, err := strconv.ParseUint(s, base, 64)
+	if err != nil {
+		// The number is too large to be an IPv4 address.
+		return 0, ErrInvalidInput
+	}
+	return n, nil
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLegacyIPv4(t *testing.T) {
+	legacy := []string{
+		"http://2130706433",
+		"http://0x7f.1",
+		"http://0177.0.0.1",
+		"http://127.1",
+		"http://127.0.1",
+		"http://0x7F000001",
+		"http://127.0.0.1.",
+	}
+
+	tests := []sharedTest{
+		{
+			description: "legacy notations are IP literals",
+			opt:         ForbidLoopback(),
+			hosts:       legacy,
+			expectedErr: ErrLoopback,
+		}, {
+			description: "legacy notations match subnets",
+			opt:         ForbidSubnet("127.0.0.0/8"),
+			hosts:       legacy,
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "legacy notations are IPs",
+			opt:         ForbidAnyIPs(),
+			hosts:       legacy,
+			expectedErr: ErrIPNotAllowed,
+		}, {
+			description: "legacy notations forbidden",
+			opt:         ForbidLegacyIPv4(),
+			hosts:       legacy[:len(legacy)-1],
+			expectedErr: ErrLegacyIPv4,
+		}, {
+			description: "legacy notations forbidden with the WHATWG parser",
+			opt:         ForbidLegacyIPv4(),
+			opts:        []Option{WithParser(WHATWG)},
+			hosts:       legacy[:len(legacy)-1],
+			expectedErr: ErrLegacyIPv4,
+		}, {
+			description: "legacy notations are IP literals with the WHATWG parser",
+			opt:         ForbidLoopback(),
+			opts:        []Option{WithParser(WHATWG)},
+			hosts:       legacy,
+			expectedErr: ErrLoopback,
+		}, {
+			description: "trailing dot is not a legacy notation",
+			opt:         ForbidLegacyIPv4(),
+			host:        "http://127.0.0.1.",
+		}, {
+			description: "standard notation is allowed",
+			opt:         ForbidLegacyIPv4(),
+			hosts: []string{
+				"http://127.0.0.1",
+				"http://[::1]",
+				"http://example.com",
+				"http://0x7f.example.com",
+			},
+		}, {
+			description: "invalid addresses",
+			opt:         ForbidLoopback(),
+			hosts: []string{
+				"http://256.0.0.1",
+				"http://1.2.3.4.5",
+				"http://08.0.0.1",
+				"http://example.123",
+				"http://4294967296",
+				"http://0xfg.1",
+			},
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func Test_parseWHATWGIPv4(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "2130706433", want: "127.0.0.1"},
+		{host: "0x7f.1", want: "127.0.0.1"},
+		{host: "0177.0.0.1", want: "127.0.0.1"},
+		{host: "127.1", want: "127.0.0.1"},
+		{host: "10.0x10203", want: "10.1.2.3"},
+		{host: "127.0.0x100", want: "127.0.1.0"},
+		{host: "0x", want: "0.0.0.0"},
+		{host: "1.2.3.4.", want: "1.2.3.4"},
+		{host: "255.255.255.255", want: "255.255.255.255"},
+		{host: "256.0.0.1"},
+		{host: "1.2.3.4.5"},
+		{host: "1..2"},
+		{host: "09"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.host, func(t *testing.T) {
+			got, err := parseWHATWGIPv4(tc.host)
+			if tc.want == "" {
+				assert.ErrorIs(t, err, ErrInvalidInput)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+func Test_endsInANumber(t *testing.T) {
+	assert.True(t, endsInANumber("example.123"))
+	assert.True(t, endsInANumber("example.0x1f."))
+	assert.True(t, endsInANumber("1"))
+	assert.False(t, endsInANumber("example.com"))
+	assert.False(t, endsInANumber("example.0x1g"))
+	assert.False(t, endsInANumber("."))
+}
+
+func TestForbidLegacyIPv4OptionString(t *testing.T) {
+	opt := ForbidLegacyIPv4()
+	assert.Equal(t, "ForbidLegacyIPv4()", opt.String())
+}
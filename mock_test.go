@@ -4,6 +4,7 @@
 package urlegit
 
 import (
+	"context"
 	"net"
 	"net/url"
 )
@@ -14,6 +15,7 @@ const (
 	mockLoopbackPrivateURL = "http://mock-loopback-private.com"
 	mockPrivateURL         = "http://mock-private.com"
 	mockUnsupportedURL     = "http://mock-unsupported.com"
+	mockSlowURL            = "http://mock-slow.com"
 )
 
 func mockResolver(s string) ([]net.IP, error) {
@@ -35,6 +37,28 @@ func mockResolver(s string) ([]net.IP, error) {
 	return nil, errAny
 }
 
+// mockResolverContext behaves like mockResolver, but blocks on the
+// mockSlowURL host until the context is done.
+func mockResolverContext(ctx context.Context, s string) ([]net.IP, error) {
+	if s == getFQDN(mockSlowURL) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return mockResolver(s)
+}
+
+// mockSliceResolver returns a ResolverContext that resolves every host to
+// the provided IPs.
+func mockSliceResolver(ips ...string) ResolverContext {
+	return func(context.Context, string) ([]net.IP, error) {
+		rv := make([]net.IP, 0, len(ips))
+		for _, ip := range ips {
+			rv = append(rv, net.ParseIP(ip))
+		}
+		return rv, nil
+	}
+}
+
 func getFQDN(s string) string {
 	u, err := url.Parse(s)
 	if err != nil {
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"net/netip"
+	"strings"
+)
+
+// OnlyAllowDomainNames returns an Option that only allows hostnames that
+// match at least one of the provided domain names.  The matching is the same
+// as ForbidDomainNames, including the pattern grammar: case-insensitive,
+// from the end of the hostname, with '*' matching everything in a single
+// subdomain, '**' matching any number of subdomains, '=' anchoring the match
+// to the entire hostname and '!' marking an exception.  Hostnames that match none
+// of the domain names are rejected with ErrDomainNotAllowed.
+//
+// IP literals are not domain names, so they are rejected with ErrIPNotAllowed
+// unless subnets are also provided.  Any argument that is a CIDR (or a single
+// IP address) is treated as an allowed subnet instead of a domain name, and
+// IP literals outside of every allowed subnet are rejected with
+// ErrSubnetNotAllowed.
+//
+// Example:
+//
+//	OnlyAllowDomainNames("example.com", "*.partner.net", "10.1.0.0/16")
+//
+// If a domain name or subnet is invalid then the Option will return an
+// error.
+func OnlyAllowDomainNames(patterns ...string) Option {
+	o := onlyAllowDomainNamesOption{
+		originals: patterns,
+	}
+
+	domains := make([]string, 0, len(patterns))
+	subnets := make([]configuredPrefix, 0, len(patterns))
+	for _, pattern := range patterns {
+		if subnet, ok := parseSubnet(pattern); ok {
+			subnets = append(subnets, configuredPrefix{
+				prefix:   subnet,
+				original: pattern,
+			})
+			continue
+		}
+		domains = append(domains, pattern)
+	}
+
+	if len(subnets) > 0 {
+		o.subnets = newPrefixSet(subnets)
+	}
+
+	var err error
+	o.domains, err = newDomainList(domains)
+	if err != nil {
+		return Error(err)
+	}
+
+	return o
+}
+
+// parseSubnet parses the string as a CIDR, or as an IP address which is
+// treated as a subnet of a single address.  If the string is neither, false
+// is returned.
+func parseSubnet(s string) (netip.Prefix, bool) {
+	if prefix, err := parsePrefix(s); err == nil {
+		return prefix, true
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil || addr.Zone() != "" {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
+
+type onlyAllowDomainNamesOption struct {
+	originals []string
+	domains   *domainList
+	subnets   *prefixSet
+}
+
+func (o onlyAllowDomainNamesOption) String() string {
+	b := strings.Builder{}
+
+	b.WriteString("OnlyAllowDomainNames(")
+	comma := ""
+	for _, original := range o.originals {
+		b.WriteString(comma)
+		b.WriteString("'")
+		b.WriteString(original)
+		b.WriteString("'")
+		comma = ", "
+	}
+	b.WriteString(")")
+
+	return b.String()
+}
+
+func (o onlyAllowDomainNamesOption) apply(c *Checker) {
+	c.hostRules = append(c.hostRules, onlyAllowDomainNamesHostname(o.domains).withContext())
+	c.ipBeforeRules = append(c.ipBeforeRules, onlyAllowIPLiterals(o.subnets))
+}
+
+func onlyAllowDomainNamesHostname(allow *domainList) HostVador {
+	return func(host string) error {
+		subs, err := hostnameProcess(host)
+		if err != nil {
+			return err
+		}
+
+		if allow.Match(subs) == nil {
+			return ErrDomainNotAllowed
+		}
+		return nil
+	}
+}
+
+func onlyAllowIPLiterals(subnets *prefixSet) AddrVador {
+	return func(addr netip.Addr) error {
+		if subnets == nil {
+			return ErrIPNotAllowed
+		}
+
+		if subnets.Contains(addr) {
+			return nil
+		}
+		return ErrSubnetNotAllowed
+	}
+}
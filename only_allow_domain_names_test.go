@@ -0,0 +1,108 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnlyAllowDomainNamesOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "allowed hostnames",
+			opt:         OnlyAllowDomainNames("example.com", "*.partner.net", "api.*.org"),
+			hosts: []string{
+				"http://example.com",
+				"http://www.Example.com.",
+				"http://a.partner.net",
+				"http://b.a.partner.net",
+				"http://api.example.org",
+			},
+		}, {
+			description: "hostnames that match nothing",
+			opt:         OnlyAllowDomainNames("example.com", "*.partner.net", "api.*.org"),
+			hosts: []string{
+				"http://example.org",
+				"http://com",
+				"http://partner.net",
+				"http://example.com.evil.com",
+				"http://www.example.org",
+			},
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "pattern grammar",
+			opt:         OnlyAllowDomainNames("=example.com", "api.**.partner.net", "!=api.test.partner.net"),
+			hosts: []string{
+				"http://example.com",
+				"http://api.partner.net",
+				"http://api.eu.partner.net",
+			},
+		}, {
+			description: "pattern grammar, no match",
+			opt:         OnlyAllowDomainNames("=example.com", "api.**.partner.net", "!=api.test.partner.net"),
+			hosts: []string{
+				"http://www.example.com",
+				"http://www.partner.net",
+				"http://api.test.partner.net",
+			},
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "nothing is allowed",
+			opt:         OnlyAllowDomainNames(),
+			host:        "http://example.com",
+			expectedErr: ErrDomainNotAllowed,
+		}, {
+			description: "invalid hostname",
+			opt:         OnlyAllowDomainNames("example.com"),
+			host:        "http://www..example.com",
+			expectedErr: ErrInvalidInput,
+		}, {
+			description: "ip literals rejected without subnets",
+			opt:         OnlyAllowDomainNames("example.com"),
+			hosts: []string{
+				"http://10.1.2.3",
+				"http://[::1]",
+			},
+			expectedErr: ErrIPNotAllowed,
+		}, {
+			description: "ip literals in the allowed subnets",
+			opt:         OnlyAllowDomainNames("example.com", "10.1.0.0/16", "192.168.1.1", "fd00::/8"),
+			hosts: []string{
+				"http://10.1.2.3",
+				"http://192.168.1.1",
+				"http://[fd00::1]",
+			},
+		}, {
+			description: "ip literals outside the allowed subnets",
+			opt:         OnlyAllowDomainNames("example.com", "10.1.0.0/16", "192.168.1.1", "fd00::/8"),
+			hosts: []string{
+				"http://10.2.0.1",
+				"http://192.168.1.2",
+				"http://[::1]",
+			},
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "resolved IPs are not checked",
+			opt:         OnlyAllowDomainNames("mock-loopback.com"),
+			opts:        []Option{WithResolver(mockResolver)},
+			host:        mockLoopbackURL,
+		}, {
+			description: "invalid domain",
+			opt:         OnlyAllowDomainNames("example..com"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestOnlyAllowDomainNamesOptionString(t *testing.T) {
+	opt := OnlyAllowDomainNames()
+	assert.Equal(t, "OnlyAllowDomainNames()", opt.String())
+
+	opt = OnlyAllowDomainNames("example.com", "10.0.0.0/8")
+	assert.Equal(t, "OnlyAllowDomainNames('example.com', '10.0.0.0/8')", opt.String())
+}
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
+// OnlyAllowSubnets returns an Option that only allows IP addresses inside the
+// provided subnets.  IP literals are always checked, as are the IPs resolved
+// by the Checker's resolver.  If a resolver is provided, it will be used to
+// resolve the hostname and every returned IP address must be inside one of
+// the subnets.  If a subnet is invalid then the Option will return an error.
+//
+// The error returned is ErrSubnetNotAllowed, and the value names the IP that
+// was outside of the subnets.
+//
+// CheckEmbeddedIPv4 does not apply to this Option; only the IP address itself
+// must be inside the subnets, not the IPv4 addresses embedded in it.
+func OnlyAllowSubnets(subnets []string, resolver ...Resolver) Option {
+	return onlyAllowSubnetsOption(subnets, contextResolvers(resolver)...)
+}
+
+// OnlyAllowSubnetsContext is the same as OnlyAllowSubnets, but the optional
+// resolver is a ResolverContext.
+func OnlyAllowSubnetsContext(subnets []string, resolver ...ResolverContext) Option {
+	return onlyAllowSubnetsOption(subnets, resolver...)
+}
+
+func onlyAllowSubnetsOption(subnets []string, resolver ...ResolverContext) Option {
+	o := onlyAllowSubnetOption{
+		originals: subnets,
+	}
+
+	var err error
+	o.subnets, err = parsePrefixSet(subnets)
+	if err != nil {
+		return Error(err)
+	}
+
+	switch len(resolver) {
+	case 0:
+	case 1:
+		o.r = resolver[0]
+	default:
+		return Error(fmt.Errorf("%w: only one resolver allowed", ErrInvalidInput))
+	}
+
+	return &o
+}
+
+type onlyAllowSubnetOption struct {
+	originals []string
+	subnets   *prefixSet
+	r         ResolverContext
+}
+
+func (o onlyAllowSubnetOption) String() string {
+	b := strings.Builder{}
+
+	b.WriteString("OnlyAllowSubnets(")
+	comma := ""
+	for _, original := range o.originals {
+		b.WriteString(comma)
+		b.WriteString("'")
+		b.WriteString(original)
+		b.WriteString("'")
+		comma = ", "
+	}
+	if o.r != nil {
+		b.WriteString(comma)
+		b.WriteString("resolver")
+	}
+	b.WriteString(")")
+
+	return b.String()
+}
+
+func (o onlyAllowSubnetOption) apply(c *Checker) {
+	c.ipAllowRules = append(c.ipAllowRules, onlyAllowSubnets(o.subnets))
+	if o.r != nil {
+		c.hostRules = append(c.hostRules, onlyAllowSubnetsUser(o.subnets, o.r))
+	}
+}
+
+func onlyAllowSubnets(subnets *prefixSet) AddrVador {
+	return func(addr netip.Addr) error {
+		if subnets.Contains(addr) {
+			return nil
+		}
+		return ErrSubnetNotAllowed
+	}
+}
+
+func onlyAllowSubnetsUser(subnets *prefixSet, fn ResolverContext) hostRule {
+	return func(ctx context.Context, host string) error {
+		ips, err := fn(ctx, host)
+		if err != nil {
+			return err
+		}
+
+		for _, addr := range addrsFromIPs(ips) {
+			if !subnets.Contains(addr) {
+				return &ValidationError{
+					Stage: StageResolvedIP,
+					Value: addr.String(),
+					Err:   ErrSubnetNotAllowed,
+				}
+			}
+		}
+		return nil
+	}
+}
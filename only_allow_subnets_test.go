@@ -0,0 +1,113 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnlyAllowSubnetsOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "ip literal inside",
+			opt:         OnlyAllowSubnets([]string{"10.0.0.0/8"}),
+			host:        "http://10.1.2.3",
+		}, {
+			description: "ip literal outside",
+			opt:         OnlyAllowSubnets([]string{"10.0.0.0/8"}),
+			host:        "http://192.168.1.1",
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "ipv6 literal inside",
+			opt:         OnlyAllowSubnets([]string{"10.0.0.0/8", "fd00::/8"}),
+			host:        "http://[fd00::1]",
+		}, {
+			description: "ipv4 mapped literal inside",
+			opt:         OnlyAllowSubnets([]string{"10.0.0.0/8"}),
+			host:        "http://[::ffff:10.0.0.1]",
+		}, {
+			description: "no subnets",
+			opt:         OnlyAllowSubnets(nil),
+			host:        "http://10.0.0.1",
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "hostname without a resolver",
+			opt:         OnlyAllowSubnets([]string{"10.0.0.0/8"}),
+			host:        mockPrivateURL,
+		}, {
+			description: "resolver, all inside",
+			opt:         OnlyAllowSubnets([]string{"192.168.0.0/16"}, mockResolver),
+			host:        mockPrivateURL,
+		}, {
+			description: "resolver, one outside",
+			opt:         OnlyAllowSubnets([]string{"192.168.0.0/16"}, mockResolver),
+			host:        mockPrivateLoopbackURL,
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "resolver, resolver error",
+			opt:         OnlyAllowSubnets([]string{"192.168.0.0/16"}, mockResolver),
+			host:        mockUnsupportedURL,
+			expectedErr: errAny,
+		}, {
+			description: "checker resolver, one outside",
+			opts: []Option{
+				OnlyAllowSubnets([]string{"192.168.0.0/16"}),
+				WithResolver(mockResolver),
+			},
+			host:        mockLoopbackPrivateURL,
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "context resolver, all inside",
+			opt:         OnlyAllowSubnetsContext([]string{"127.0.0.0/8", "192.168.0.0/16"}, mockResolverContext),
+			host:        mockPrivateLoopbackURL,
+		}, {
+			description: "invalid subnet",
+			opt:         OnlyAllowSubnets([]string{"10.0.0.0/8", "10.0.0.0"}),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		}, {
+			description: "too many resolvers",
+			opt:         OnlyAllowSubnetsContext([]string{"10.0.0.0/8"}, mockResolverContext, mockResolverContext),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestOnlyAllowSubnetsError(t *testing.T) {
+	c, err := New(OnlyAllowSubnets([]string{"192.168.0.0/16"}, mockResolver))
+	require.NoError(t, err)
+
+	err = c.Text(mockPrivateLoopbackURL)
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.ErrorIs(t, err, ErrSubnetNotAllowed)
+	assert.Equal(t, StageResolvedIP, ve.Stage)
+	assert.Equal(t, "127.0.0.1", ve.Value)
+	assert.Equal(t, "OnlyAllowSubnets('192.168.0.0/16', resolver)", ve.Option)
+
+	c, err = New(OnlyAllowSubnets([]string{"192.168.0.0/16"}))
+	require.NoError(t, err)
+
+	err = c.Text("http://10.0.0.1")
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, StageIPLiteral, ve.Stage)
+	assert.Equal(t, "10.0.0.1", ve.Value)
+}
+
+func TestOnlyAllowSubnetsOptionString(t *testing.T) {
+	opt := OnlyAllowSubnets([]string{"10.0.0.0/8"})
+	assert.Equal(t, "OnlyAllowSubnets('10.0.0.0/8')", opt.String())
+
+	opt = OnlyAllowSubnets([]string{"10.0.0.0/8", "fd00::/8"}, mockResolver)
+	assert.Equal(t, "OnlyAllowSubnets('10.0.0.0/8', 'fd00::/8', resolver)", opt.String())
+
+	opt = OnlyAllowSubnetsContext(nil, mockResolverContext)
+	assert.Equal(t, "OnlyAllowSubnets(resolver)", opt.String())
+}
@@ -4,6 +4,7 @@
 package urlegit
 
 import (
+	"context"
 	"net"
 )
 
@@ -13,21 +14,56 @@ import (
 // To use the default go resolver, use the net.LookupIP function.
 type Resolver func(host string) ([]net.IP, error)
 
+// ResolverContext is a function that returns a list of IP addresses for a
+// given host.  The context's deadline and cancellation should be honored by
+// the lookup.
+//
+// To use the default go resolver, wrap net.DefaultResolver.LookupIP with the
+// "ip" network.
+type ResolverContext func(ctx context.Context, host string) ([]net.IP, error)
+
+// withContext adapts a Resolver into a ResolverContext.  The Resolver cannot
+// be interrupted, so the context is only checked before the lookup starts.
+func (r Resolver) withContext() ResolverContext {
+	if r == nil {
+		return nil
+	}
+	return func(ctx context.Context, host string) ([]net.IP, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return r(host)
+	}
+}
+
 // WithResolver returns an Option that will use the given Resolver to resolve
 // hostnames into IP addresses.
 func WithResolver(r Resolver) Option {
-	return resolverOption{r: r}
+	return resolverOption{
+		name: "WithResolver",
+		r:    r.withContext(),
+	}
+}
+
+// WithResolverContext returns an Option that will use the given
+// ResolverContext to resolve hostnames into IP addresses.
+func WithResolverContext(r ResolverContext) Option {
+	return resolverOption{
+		name: "WithResolverContext",
+		r:    r,
+	}
 }
 
 type resolverOption struct {
-	r Resolver
+	name string
+	r    ResolverContext
 }
 
 func (r resolverOption) String() string {
 	if r.r == nil {
-		return "WithResolver(nil)"
+		return r.name + "(nil)"
 	}
-	return "WithResolver(resolver)"
+	return r.name + "(resolver)"
 }
 
 func (r resolverOption) apply(c *Checker) {
@@ -88,7 +124,43 @@ func (o customHostVadorOption) String() string {
 }
 
 func (o customHostVadorOption) apply(c *Checker) {
-	c.hostRules = append(c.hostRules, o.h)
+	c.hostRules = append(c.hostRules, o.h.withContext())
+}
+
+// CustomPathVador returns an Option that will use the given PathVador
+// to validate paths.
+func CustomPathVador(p PathVador) Option {
+	return customPathVadorOption{p: p}
+}
+
+type customPathVadorOption struct {
+	p PathVador
+}
+
+func (o customPathVadorOption) String() string {
+	return "CustomPathVador(vador)"
+}
+
+func (o customPathVadorOption) apply(c *Checker) {
+	c.pathRules = append(c.pathRules, o.p)
+}
+
+// CustomQueryVador returns an Option that will use the given QueryVador
+// to validate queries.
+func CustomQueryVador(q QueryVador) Option {
+	return customQueryVadorOption{q: q}
+}
+
+type customQueryVadorOption struct {
+	q QueryVador
+}
+
+func (o customQueryVadorOption) String() string {
+	return "CustomQueryVador(vador)"
+}
+
+func (o customQueryVadorOption) apply(c *Checker) {
+	c.queryRules = append(c.queryRules, o.q.withRaw())
 }
 
 // CustomIPVador returns an Option that will use the given IPVador
@@ -106,5 +178,23 @@ func (o customIPVadorOption) String() string {
 }
 
 func (o customIPVadorOption) apply(c *Checker) {
-	c.ipRules = append(c.ipRules, o.i)
+	c.ipRules = append(c.ipRules, o.i.withAddr())
+}
+
+// CustomAddrVador returns an Option that will use the given AddrVador
+// to validate IPs.
+func CustomAddrVador(a AddrVador) Option {
+	return customAddrVadorOption{a: a}
+}
+
+type customAddrVadorOption struct {
+	a AddrVador
+}
+
+func (o customAddrVadorOption) String() string {
+	return "CustomAddrVador(vador)"
+}
+
+func (o customAddrVadorOption) apply(c *Checker) {
+	c.ipRules = append(c.ipRules, o.a)
 }
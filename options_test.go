@@ -5,6 +5,8 @@ package urlegit
 
 import (
 	"net"
+	"net/netip"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -35,6 +37,27 @@ func customIPVador(ip *net.IP) error {
 	return nil
 }
 
+func customAddrVador(addr netip.Addr) error {
+	if addr.Zone() != "" || addr == netip.MustParseAddr("192.168.1.1") {
+		return ErrIPNotAllowed
+	}
+	return nil
+}
+
+func customPathVador(p string) error {
+	if p == "/private" {
+		return ErrPathNotAllowed
+	}
+	return nil
+}
+
+func customQueryVador(q url.Values) error {
+	if q.Has("debug") {
+		return errAny
+	}
+	return nil
+}
+
 func TestResolverOptionString(t *testing.T) {
 	opt := WithResolver(nil)
 	assert.Equal(t, "WithResolver(nil)", opt.String())
@@ -43,6 +66,14 @@ func TestResolverOptionString(t *testing.T) {
 	assert.Equal(t, "WithResolver(resolver)", opt.String())
 }
 
+func TestResolverContextOptionString(t *testing.T) {
+	opt := WithResolverContext(nil)
+	assert.Equal(t, "WithResolverContext(nil)", opt.String())
+
+	opt = WithResolverContext(mockResolverContext)
+	assert.Equal(t, "WithResolverContext(resolver)", opt.String())
+}
+
 func TestCustomSchemeVador(t *testing.T) {
 	tests := []sharedTest{
 		{
@@ -107,3 +138,84 @@ func TestCustomIPVadorString(t *testing.T) {
 	opt := CustomIPVador(customIPVador)
 	assert.Equal(t, "CustomIPVador(vador)", opt.String())
 }
+
+func TestCustomAddrVador(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "use custom addr vador, no match",
+			opt:         CustomAddrVador(customAddrVador),
+			host:        "http://192.168.1.2",
+		}, {
+			description: "use custom addr vador, match",
+			opt:         CustomAddrVador(customAddrVador),
+			host:        "http://192.168.1.1",
+			expectedErr: ErrIPNotAllowed,
+		}, {
+			description: "use custom addr vador, ipv4 mapped match",
+			opt:         CustomAddrVador(customAddrVador),
+			host:        "http://[::ffff:192.168.1.1]",
+			expectedErr: ErrIPNotAllowed,
+		}, {
+			description: "use custom addr vador, zone match",
+			opt:         CustomAddrVador(customAddrVador),
+			host:        "http://[fe80::1%25eth0]",
+			expectedErr: ErrIPNotAllowed,
+		}, {
+			description: "use custom addr vador, resolved match",
+			opts: []Option{
+				CustomAddrVador(customAddrVador),
+				WithResolver(mockResolver),
+			},
+			host:        mockPrivateLoopbackURL,
+			expectedErr: ErrIPNotAllowed,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestCustomAddrVadorString(t *testing.T) {
+	opt := CustomAddrVador(customAddrVador)
+	assert.Equal(t, "CustomAddrVador(vador)", opt.String())
+}
+
+func TestCustomPathVador(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "use custom path vador, no match",
+			opt:         CustomPathVador(customPathVador),
+			host:        "http://example.com/public",
+		}, {
+			description: "use custom path vador, match",
+			opt:         CustomPathVador(customPathVador),
+			host:        "http://example.com/%70rivate",
+			expectedErr: ErrPathNotAllowed,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestCustomPathVadorString(t *testing.T) {
+	opt := CustomPathVador(customPathVador)
+	assert.Equal(t, "CustomPathVador(vador)", opt.String())
+}
+
+func TestCustomQueryVador(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "use custom query vador, no match",
+			opt:         CustomQueryVador(customQueryVador),
+			host:        "http://example.com/?a=1",
+		}, {
+			description: "use custom query vador, match",
+			opt:         CustomQueryVador(customQueryVador),
+			host:        "http://example.com/?a=1&debug",
+			expectedErr: errAny,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestCustomQueryVadorString(t *testing.T) {
+	opt := CustomQueryVador(customQueryVador)
+	assert.Equal(t, "CustomQueryVador(vador)", opt.String())
+}
@@ -0,0 +1,294 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Parser is a function that parses a string into a URL.  The parser used by
+// Checker.Text() determines which host the rules are applied to.
+//
+// Two parsers are provided: url.Parse, which is the default, and WHATWG.
+type Parser func(s string) (*url.URL, error)
+
+// WithParser returns an Option that will use the given Parser to parse the
+// strings passed to Checker.Text() and Checker.Legit().  If the Parser is nil,
+// url.Parse is used.
+func WithParser(p Parser) Option {
+	return parserOption{p: p}
+}
+
+type parserOption struct {
+	p Parser
+}
+
+func (o parserOption) String() string {
+	if o.p == nil {
+		return "WithParser(nil)"
+	}
+	return "WithParser(parser)"
+}
+
+func (o parserOption) apply(c *Checker) {
+	c.parser = o.p
+}
+
+// specialSchemes are the schemes the WHATWG URL specification treats as
+// special, along with their default ports.  They are the schemes with well
+// known ports, plus "file" which has no port.
+var specialSchemes = func() map[string]string {
+	m := map[string]string{
+		"file": "",
+	}
+	for scheme, port := range defaultPorts {
+		m[scheme] = port
+	}
+	return m
+}()
+
+// WHATWG parses the string following the host and authority parsing rules of
+// the WHATWG URL specification, which is how browsers and many other HTTP
+// stacks parse URLs.  Compared to url.Parse:
+//
+//   - leading and trailing control characters and spaces are removed
+//   - tabs and newlines are removed everywhere
+//   - for special schemes (http, https, ws, wss, ftp, file) a backslash is
+//     treated as a slash before the query and any number of slashes may
+//     precede the authority
+//   - the host is percent-decoded, converted to ASCII using UTS #46 and
+//     checked for forbidden code points
+//   - hosts that end in a number must be valid IPv4 addresses; legacy
+//     notations such as "0x7f.1" are left as they are so ForbidLegacyIPv4
+//     can reject them, and are otherwise checked as the dotted-decimal
+//     address
+//   - the default port of the scheme is removed
+//
+// Non-special schemes are parsed by url.Parse after the control characters,
+// tabs and newlines are removed.  Relative URLs are not supported.
+//
+// See https://url.spec.whatwg.org/#url-parsing
+func WHATWG(s string) (*url.URL, error) {
+	s = strings.TrimFunc(s, func(r rune) bool {
+		return r <= 0x20
+	})
+	s = strings.Map(func(r rune) rune {
+		if r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+
+	scheme, rest, ok := cutScheme(s)
+	if !ok {
+		return nil, fmt.Errorf("%w: missing scheme in '%s'", ErrInvalidInput, s)
+	}
+
+	defaultPort, special := specialSchemes[scheme]
+	if !special {
+		return url.Parse(s)
+	}
+
+	// A backslash is a path separator everywhere before the query and
+	// fragment.
+	end := strings.IndexAny(rest, "?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	rest = strings.ReplaceAll(rest[:end], "\\", "/") + rest[end:]
+
+	rest = strings.TrimLeft(rest, "/")
+	end = strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	authority, rest := rest[:end], rest[end:]
+
+	u := url.URL{
+		Scheme: scheme,
+	}
+
+	if i := strings.LastIndex(authority, "@"); i >= 0 {
+		userinfo := authority[:i]
+		authority = authority[i+1:]
+
+		user, pass, hasPass := strings.Cut(userinfo, ":")
+		user, _ = url.PathUnescape(user)
+		pass, _ = url.PathUnescape(pass)
+		u.User = url.User(user)
+		if hasPass {
+			u.User = url.UserPassword(user, pass)
+		}
+	}
+
+	host, port, err := splitWHATWGHostPort(authority)
+	if err != nil {
+		return nil, err
+	}
+
+	if host == "" && scheme != "file" {
+		return nil, fmt.Errorf("%w: empty host in '%s'", ErrInvalidInput, s)
+	}
+
+	host, err = parseWHATWGHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	if port == defaultPort {
+		port = ""
+	}
+
+	u.Host = host
+	if port != "" {
+		u.Host += ":" + port
+	}
+
+	if err := parseWHATWGPath(&u, rest); err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+// cutScheme splits the string into the lowercase scheme and the remainder
+// after the ':'.
+func cutScheme(s string) (scheme, rest string, ok bool) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		case i > 0 && ('0' <= c && c <= '9' || c == '+' || c == '-' || c == '.'):
+		case i > 0 && c == ':':
+			return strings.ToLower(s[:i]), s[i+1:], true
+		default:
+			return "", "", false
+		}
+	}
+	return "", "", false
+}
+
+// splitWHATWGHostPort splits the authority (without userinfo) into the host
+// and port.  The port is validated and normalized.
+func splitWHATWGHostPort(authority string) (host, port string, err error) {
+	host = authority
+	i := strings.LastIndex(authority, ":")
+	if i >= 0 && !strings.Contains(authority[i:], "]") {
+		host, port = authority[:i], authority[i+1:]
+	}
+
+	if port == "" {
+		return host, "", nil
+	}
+
+	for _, r := range port {
+		if r < '0' || r > '9' {
+			return "", "", fmt.Errorf("%w: invalid port '%s'", ErrInvalidInput, port)
+		}
+	}
+
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: invalid port '%s'", ErrInvalidInput, port)
+	}
+
+	return host, strconv.FormatUint(n, 10), nil
+}
+
+// parseWHATWGHost implements the WHATWG host parser for special schemes,
+// returning the serialized host.
+//
+// See https://url.spec.whatwg.org/#host-parsing
+func parseWHATWGHost(host string) (string, error) {
+	if strings.HasPrefix(host, "[") {
+		if !strings.HasSuffix(host, "]") {
+			return "", fmt.Errorf("%w: invalid IPv6 address '%s'", ErrInvalidInput, host)
+		}
+		ip, err := netip.ParseAddr(host[1 : len(host)-1])
+		if err != nil || !ip.Is6() || ip.Zone() != "" {
+			return "", fmt.Errorf("%w: invalid IPv6 address '%s'", ErrInvalidInput, host)
+		}
+		return "[" + ip.String() + "]", nil
+	}
+
+	decoded, err := url.PathUnescape(host)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid host '%s'", ErrInvalidInput, host)
+	}
+
+	ascii, err := toASCII(decoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid host '%s'", ErrInvalidIDN, host)
+	}
+
+	for _, r := range ascii {
+		if isForbiddenDomainCodePoint(r) {
+			return "", fmt.Errorf("%w: forbidden code point %q in host '%s'", ErrInvalidInput, r, host)
+		}
+	}
+
+	// The notation is validated but kept, so ForbidLegacyIPv4 can still see
+	// it; the Checker normalizes the address itself.
+	if endsInANumber(ascii) {
+		if _, err := parseWHATWGIPv4(ascii); err != nil {
+			return "", err
+		}
+	}
+
+	return ascii, nil
+}
+
+// isForbiddenDomainCodePoint returns true for the WHATWG forbidden domain
+// code points.
+//
+// See https://url.spec.whatwg.org/#forbidden-domain-code-point
+func isForbiddenDomainCodePoint(r rune) bool {
+	if r <= 0x20 || r == 0x7f {
+		return true
+	}
+	return strings.ContainsRune("#%/:<>?@[\\]^|", r)
+}
+
+// parseWHATWGPath parses the path, query and fragment into the URL.  Percent
+// signs that do not start a valid escape and spaces are encoded first, since
+// the WHATWG parser accepts them but url.Parse does not.
+func parseWHATWGPath(u *url.URL, s string) error {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '%' && (i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2])):
+			b.WriteString("%25")
+		case s[i] == ' ':
+			b.WriteString("%20")
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+
+	// The authority has already been parsed, so a placeholder is used to
+	// keep a path starting with "//" from being parsed as one.
+	p, err := url.Parse("http://placeholder" + b.String())
+	if err != nil {
+		return err
+	}
+
+	u.Path = p.Path
+	u.RawPath = p.RawPath
+	u.RawQuery = p.RawQuery
+	u.ForceQuery = p.ForceQuery
+	u.Fragment = p.Fragment
+	u.RawFragment = p.RawFragment
+	if u.Path == "" && u.Scheme != "file" {
+		u.Path = "/"
+	}
+	return nil
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
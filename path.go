@@ -0,0 +1,209 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// maxPathDecodes is the number of times a path is percent decoded looking
+// for a traversal.  Each layer of encoding can be removed by a different
+// proxy or server along the way.
+const maxPathDecodes = 3
+
+// ForbidPathTraversal returns an Option that disallows paths with a ".."
+// segment.  The path is percent decoded repeatedly, so "%2e%2e" and
+// "%252e%252e" are found, and both '/' and '\' are treated as separators.
+func ForbidPathTraversal() Option {
+	return forbidPathTraversalOption{}
+}
+
+type forbidPathTraversalOption struct{}
+
+func (forbidPathTraversalOption) String() string {
+	return "ForbidPathTraversal()"
+}
+
+func (forbidPathTraversalOption) apply(c *Checker) {
+	c.pathRules = append(c.pathRules, forbidPathTraversal)
+}
+
+func forbidPathTraversal(p string) error {
+	for i := 0; ; i++ {
+		segments := strings.FieldsFunc(p, func(r rune) bool {
+			return r == '/' || r == '\\'
+		})
+		for _, segment := range segments {
+			if segment == ".." {
+				return ErrPathTraversal
+			}
+		}
+
+		if i >= maxPathDecodes {
+			return nil
+		}
+
+		decoded, err := url.PathUnescape(p)
+		if err != nil || decoded == p {
+			return nil
+		}
+		p = decoded
+	}
+}
+
+// OnlyAllowPaths returns an Option that only allows paths that match one of
+// the provided glob patterns.  Each '/' separated segment of a pattern is
+// matched using path.Match(), and a "**" segment matches any number of
+// segments, including none.  For example "/api/v1/*" matches "/api/v1/users"
+// and "/static/**" matches "/static/css/site.css".
+//
+// The decoded path is cleaned with path.Clean() before it is matched, so
+// "/static/../admin" is matched as "/admin", and an empty path is matched as
+// "/".  If a pattern is invalid then the Option will return an error.
+func OnlyAllowPaths(patterns ...string) Option {
+	return pathGlobsOption("OnlyAllowPaths", true, patterns)
+}
+
+// ForbidPaths returns an Option that disallows paths that match one of the
+// provided glob patterns.  The patterns are the same as OnlyAllowPaths.
+func ForbidPaths(patterns ...string) Option {
+	return pathGlobsOption("ForbidPaths", false, patterns)
+}
+
+func pathGlobsOption(name string, allow bool, patterns []string) Option {
+	o := pathGlobOption{
+		optName: name,
+		allow:   allow,
+		globs:   make([]*pathGlob, 0, len(patterns)),
+	}
+
+	for _, pattern := range patterns {
+		g, err := newPathGlob(pattern)
+		if err != nil {
+			return Error(err)
+		}
+		o.globs = append(o.globs, g)
+	}
+
+	return o
+}
+
+type pathGlobOption struct {
+	optName string
+	allow   bool
+	globs   []*pathGlob
+}
+
+func (o pathGlobOption) String() string {
+	b := strings.Builder{}
+
+	b.WriteString(o.optName)
+	b.WriteString("(")
+	comma := ""
+	for _, g := range o.globs {
+		b.WriteString(comma)
+		b.WriteString("'")
+		b.WriteString(g.original)
+		b.WriteString("'")
+		comma = ", "
+	}
+	b.WriteString(")")
+
+	return b.String()
+}
+
+func (o pathGlobOption) apply(c *Checker) {
+	if o.allow {
+		c.pathRules = append(c.pathRules, onlyAllowPaths(o.globs))
+		return
+	}
+	c.pathRules = append(c.pathRules, forbidPaths(o.globs))
+}
+
+func onlyAllowPaths(globs []*pathGlob) PathVador {
+	return func(p string) error {
+		segments := pathSegments(p)
+		for _, g := range globs {
+			if g.Match(segments) {
+				return nil
+			}
+		}
+		return ErrPathNotAllowed
+	}
+}
+
+func forbidPaths(globs []*pathGlob) PathVador {
+	return func(p string) error {
+		segments := pathSegments(p)
+		for _, g := range globs {
+			if g.Match(segments) {
+				return matched(ErrPathNotAllowed, g.original)
+			}
+		}
+		return nil
+	}
+}
+
+// pathGlob is a path pattern split into segments.
+type pathGlob struct {
+	original string
+	segments []string
+}
+
+func newPathGlob(s string) (*pathGlob, error) {
+	g := pathGlob{
+		original: s,
+		segments: strings.Split(strings.TrimPrefix(s, "/"), "/"),
+	}
+
+	for i, segment := range g.segments {
+		if segment == "**" {
+			if i > 0 && g.segments[i-1] == "**" {
+				return nil, fmt.Errorf("%w: invalid path pattern '%s' repeated '**' segments", ErrInvalidInput, s)
+			}
+			continue
+		}
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("%w: invalid path pattern '%s' %v", ErrInvalidInput, s, err)
+		}
+	}
+
+	return &g, nil
+}
+
+// Match returns true if the pattern matches all of the path segments.
+func (g *pathGlob) Match(segments []string) bool {
+	return matchSegments(g.segments, segments)
+}
+
+func matchSegments(pattern, target []string) bool {
+	for i, segment := range pattern {
+		if segment == "**" {
+			for j := i; j <= len(target); j++ {
+				if matchSegments(pattern[i+1:], target[j:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if i >= len(target) {
+			return false
+		}
+		if ok, _ := path.Match(segment, target[i]); !ok {
+			return false
+		}
+	}
+
+	return len(pattern) == len(target)
+}
+
+// pathSegments cleans the path and splits it into segments.
+func pathSegments(p string) []string {
+	p = path.Clean("/" + p)
+	return strings.Split(strings.TrimPrefix(p, "/"), "/")
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForbidPathTraversalOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "no traversal",
+			opt:         ForbidPathTraversal(),
+			host:        "http://example.com/a/b..c/.d",
+		}, {
+			description: "empty path",
+			opt:         ForbidPathTraversal(),
+			host:        "http://example.com",
+		}, {
+			description: "traversal",
+			opt:         ForbidPathTraversal(),
+			host:        "http://example.com/a/../b",
+			expectedErr: ErrPathTraversal,
+		}, {
+			description: "trailing traversal",
+			opt:         ForbidPathTraversal(),
+			host:        "http://example.com/a/..",
+			expectedErr: ErrPathTraversal,
+		}, {
+			description: "encoded traversal",
+			opt:         ForbidPathTraversal(),
+			host:        "http://example.com/a/%2e%2E/b",
+			expectedErr: ErrPathTraversal,
+		}, {
+			description: "double encoded traversal",
+			opt:         ForbidPathTraversal(),
+			host:        "http://example.com/a/%252e%252e/b",
+			expectedErr: ErrPathTraversal,
+		}, {
+			description: "encoded separator",
+			opt:         ForbidPathTraversal(),
+			host:        "http://example.com/a%2f..%2fb",
+			expectedErr: ErrPathTraversal,
+		}, {
+			description: "backslash separator",
+			opt:         ForbidPathTraversal(),
+			host:        `http://example.com/a/..%5cb`,
+			expectedErr: ErrPathTraversal,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestOnlyAllowPathsOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "exact match",
+			opt:         OnlyAllowPaths("/api/v1/users"),
+			host:        "http://example.com/api/v1/users",
+		}, {
+			description: "single segment wildcard",
+			opt:         OnlyAllowPaths("/api/v1/*"),
+			host:        "http://example.com/api/v1/users",
+		}, {
+			description: "single segment wildcard, too deep",
+			opt:         OnlyAllowPaths("/api/v1/*"),
+			host:        "http://example.com/api/v1/users/1",
+			expectedErr: ErrPathNotAllowed,
+		}, {
+			description: "globstar",
+			opt:         OnlyAllowPaths("/static/**"),
+			host:        "http://example.com/static/css/site.css",
+		}, {
+			description: "globstar matches nothing",
+			opt:         OnlyAllowPaths("/static/**"),
+			host:        "http://example.com/static",
+		}, {
+			description: "globstar in the middle",
+			opt:         OnlyAllowPaths("/a/**/*.png"),
+			host:        "http://example.com/a/b/c/d.png",
+		}, {
+			description: "empty path is the root",
+			opt:         OnlyAllowPaths("/"),
+			host:        "http://example.com",
+		}, {
+			description: "cleaned before matching",
+			opt:         OnlyAllowPaths("/static/**"),
+			host:        "http://example.com/static/../admin",
+			expectedErr: ErrPathNotAllowed,
+		}, {
+			description: "no match",
+			opt:         OnlyAllowPaths("/api/**", "/static/**"),
+			host:        "http://example.com/admin",
+			expectedErr: ErrPathNotAllowed,
+		}, {
+			description: "invalid pattern",
+			opt:         OnlyAllowPaths("/api/["),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		}, {
+			description: "repeated globstar",
+			opt:         OnlyAllowPaths("/api/**/**"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidPathsOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "no match",
+			opt:         ForbidPaths("/admin/**", "/*.php"),
+			host:        "http://example.com/api/users",
+		}, {
+			description: "match",
+			opt:         ForbidPaths("/admin/**", "/*.php"),
+			host:        "http://example.com/admin/users",
+			expectedErr: ErrPathNotAllowed,
+		}, {
+			description: "match with a wildcard",
+			opt:         ForbidPaths("/admin/**", "/*.php"),
+			host:        "http://example.com/index.php",
+			expectedErr: ErrPathNotAllowed,
+		}, {
+			description: "match after decoding",
+			opt:         ForbidPaths("/admin/**"),
+			host:        "http://example.com/%61dmin",
+			expectedErr: ErrPathNotAllowed,
+		}, {
+			description: "invalid pattern",
+			opt:         ForbidPaths("/[a-"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidPathsError(t *testing.T) {
+	c, err := New(ForbidPaths("/admin/**"))
+	require.NoError(t, err)
+
+	err = c.Text("http://example.com/admin/users")
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, StagePath, ve.Stage)
+	assert.Equal(t, "/admin/users", ve.Value)
+	assert.Equal(t, "/admin/**", ve.Pattern)
+	assert.Equal(t, "ForbidPaths('/admin/**')", ve.Option)
+}
+
+func TestPathOptionString(t *testing.T) {
+	opt := ForbidPathTraversal()
+	assert.Equal(t, "ForbidPathTraversal()", opt.String())
+
+	opt = OnlyAllowPaths("/api/**", "/")
+	assert.Equal(t, "OnlyAllowPaths('/api/**', '/')", opt.String())
+
+	opt = ForbidPaths("/admin/**")
+	assert.Equal(t, "ForbidPaths('/admin/**')", opt.String())
+}
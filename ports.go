@@ -0,0 +1,219 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// OnlyAllowPorts returns an Option that only allows the provided ports.  Each
+// port is either a single port such as "443" or an inclusive range such as
+// "8000-8999".  If the URL does not specify a port, the well known port of
+// the scheme is checked instead, and a URL with neither is rejected.  If a
+// port is invalid then the Option will return an error.
+//
+// When any port Option is used, a URL with a port that is not a number from 1
+// to 65535, such as "http://example.com:0", is rejected with ErrInvalidPort.
+// Without a port Option the port is not checked.
+func OnlyAllowPorts(ports ...string) Option {
+	return portsOption("OnlyAllowPorts", true, ports)
+}
+
+// ForbidPorts returns an Option that disallows the provided ports.  The ports
+// are specified the same way as OnlyAllowPorts.  If the URL does not specify
+// a port, the well known port of the scheme is checked instead.  Invalid
+// ports are rejected the same way as OnlyAllowPorts.
+func ForbidPorts(ports ...string) Option {
+	return portsOption("ForbidPorts", false, ports)
+}
+
+func portsOption(name string, allow bool, ports []string) Option {
+	o := portRangesOption{
+		optName:   name,
+		allow:     allow,
+		originals: ports,
+		ranges:    make([]portRange, 0, len(ports)),
+	}
+
+	for _, port := range ports {
+		r, err := parsePortRange(port)
+		if err != nil {
+			return Error(err)
+		}
+		o.ranges = append(o.ranges, r)
+	}
+
+	return o
+}
+
+type portRangesOption struct {
+	optName   string
+	allow     bool
+	originals []string
+	ranges    []portRange
+}
+
+func (o portRangesOption) String() string {
+	return o.optName + "('" + strings.Join(o.originals, "', '") + "')"
+}
+
+func (o portRangesOption) apply(c *Checker) {
+	if o.allow {
+		c.portRules = append(c.portRules, onlyAllowPorts(o.ranges))
+		return
+	}
+	c.portRules = append(c.portRules, forbidPorts(o.ranges))
+}
+
+func onlyAllowPorts(ranges []portRange) portRule {
+	return func(scheme, port string) error {
+		n, err := parsePort(effectivePort(scheme, port))
+		if err != nil {
+			return ErrPortNotAllowed
+		}
+
+		for _, r := range ranges {
+			if r.Contains(n) {
+				return nil
+			}
+		}
+		return ErrPortNotAllowed
+	}
+}
+
+func forbidPorts(ranges []portRange) portRule {
+	return func(scheme, port string) error {
+		n, err := parsePort(effectivePort(scheme, port))
+		if err != nil {
+			return nil
+		}
+
+		for _, r := range ranges {
+			if r.Contains(n) {
+				return matched(ErrPortNotAllowed, r.original)
+			}
+		}
+		return nil
+	}
+}
+
+// ForbidSchemePortMismatch returns an Option that disallows a URL whose port
+// is the well known port of a different scheme, such as "https://host:80" or
+// "http://host:443".  Ports that are not well known for any scheme, and URLs
+// without a port, are not affected.  Invalid ports are rejected the same way
+// as OnlyAllowPorts.
+func ForbidSchemePortMismatch() Option {
+	return forbidSchemePortMismatchOption{}
+}
+
+type forbidSchemePortMismatchOption struct{}
+
+func (forbidSchemePortMismatchOption) String() string {
+	return "ForbidSchemePortMismatch()"
+}
+
+func (forbidSchemePortMismatchOption) apply(c *Checker) {
+	c.portRules = append(c.portRules, forbidSchemePortMismatch)
+}
+
+func forbidSchemePortMismatch(scheme, port string) error {
+	n, err := parsePort(port)
+	if err != nil {
+		return nil
+	}
+
+	// Leading zeros do not make the port a different port.
+	port = strconv.Itoa(n)
+	if defaultPorts[scheme] == port {
+		return nil
+	}
+
+	// Report the owners in a stable order.
+	owners := make([]string, 0, len(defaultPorts))
+	for s, p := range defaultPorts {
+		if p == port {
+			owners = append(owners, s)
+		}
+	}
+	if len(owners) == 0 {
+		return nil
+	}
+	sort.Strings(owners)
+
+	return matched(ErrSchemePortMismatch, strings.Join(owners, ", "))
+}
+
+// urlPort returns the port of the URL.  Unlike url.URL.Port(), a port that
+// is not numeric is returned instead of being left in the hostname.
+func urlPort(u *url.URL) string {
+	host := u.Host
+	if strings.HasPrefix(host, "[") {
+		if i := strings.LastIndexByte(host, ']'); i >= 0 {
+			host = host[i+1:]
+		}
+	} else if strings.Count(host, ":") > 1 {
+		// An unbracketed IPv6 address has no port.
+		return ""
+	}
+
+	_, port, _ := strings.Cut(host, ":")
+	return port
+}
+
+// effectivePort returns the port, or the well known port of the scheme if
+// the port is empty.
+func effectivePort(scheme, port string) string {
+	if port == "" {
+		return defaultPorts[scheme]
+	}
+	return port
+}
+
+// parsePort parses a port, which must be a decimal number from 1 to 65535.
+func parsePort(s string) (int, error) {
+	if s == "" || strings.Trim(s, "0123456789") != "" {
+		return 0, fmt.Errorf("%w: '%s'", ErrInvalidPort, s)
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 1 || n > 65535 {
+		return 0, fmt.Errorf("%w: '%s'", ErrInvalidPort, s)
+	}
+	return n, nil
+}
+
+// portRange is an inclusive range of ports.
+type portRange struct {
+	original string
+	lo, hi   int
+}
+
+func parsePortRange(s string) (portRange, error) {
+	r := portRange{original: s}
+
+	lo, hi, isRange := strings.Cut(s, "-")
+	var err error
+	r.lo, err = parsePort(lo)
+	if err != nil {
+		return r, fmt.Errorf("%w: invalid port range '%s'", ErrInvalidInput, s)
+	}
+
+	r.hi = r.lo
+	if isRange {
+		r.hi, err = parsePort(hi)
+		if err != nil || r.hi < r.lo {
+			return r, fmt.Errorf("%w: invalid port range '%s'", ErrInvalidInput, s)
+		}
+	}
+	return r, nil
+}
+
+// Contains returns true if the port is in the range.
+func (r portRange) Contains(port int) bool {
+	return r.lo <= port && port <= r.hi
+}
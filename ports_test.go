@@ -0,0 +1,265 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInvalidPorts(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "valid port",
+			opt:         ForbidPorts("22"),
+			host:        "http://example.com:8080",
+		}, {
+			description: "empty port",
+			opt:         ForbidPorts("22"),
+			host:        "http://example.com:",
+		}, {
+			description: "port zero",
+			opt:         ForbidPorts("22"),
+			host:        "http://example.com:0",
+			expectedErr: ErrInvalidPort,
+		}, {
+			description: "port too large",
+			opt:         OnlyAllowPorts("1-65535"),
+			host:        "http://example.com:65536",
+			expectedErr: ErrInvalidPort,
+		}, {
+			description: "ipv6 port too large",
+			opt:         ForbidSchemePortMismatch(),
+			host:        "http://[::1]:99999",
+			expectedErr: ErrInvalidPort,
+		}, {
+			description: "invalid ports are not checked without a port option",
+			hosts: []string{
+				"http://example.com:0",
+				"http://example.com:65536",
+			},
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestInvalidPortNotNumeric(t *testing.T) {
+	c, err := New(ForbidPorts("22"))
+	require.NoError(t, err)
+
+	for _, host := range []string{"example.com:http", "[::1]:abc", "example.com:-1"} {
+		t.Run(host, func(t *testing.T) {
+			err := c.URL(&url.URL{Scheme: "http", Host: host})
+			assert.ErrorIs(t, err, ErrInvalidPort)
+
+			var ve *ValidationError
+			require.True(t, errors.As(err, &ve))
+			assert.Equal(t, StagePort, ve.Stage)
+		})
+	}
+}
+
+func TestOnlyAllowPortsOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "default port allowed",
+			opt:         OnlyAllowPorts("80", "443"),
+			host:        "http://example.com",
+		}, {
+			description: "explicit port allowed",
+			opt:         OnlyAllowPorts("80", "443"),
+			host:        "http://example.com:443",
+		}, {
+			description: "port in range",
+			opt:         OnlyAllowPorts("80", "8000-8999"),
+			host:        "http://example.com:8500",
+		}, {
+			description: "port not allowed",
+			opt:         OnlyAllowPorts("80", "8000-8999"),
+			host:        "http://example.com:6379",
+			expectedErr: ErrPortNotAllowed,
+		}, {
+			description: "default port not allowed",
+			opt:         OnlyAllowPorts("8080"),
+			host:        "http://example.com",
+			expectedErr: ErrPortNotAllowed,
+		}, {
+			description: "no known port",
+			opt:         OnlyAllowPorts("80"),
+			noHttp:      true,
+			host:        "gopher://example.com",
+			expectedErr: ErrPortNotAllowed,
+		}, {
+			description: "invalid port",
+			opt:         OnlyAllowPorts("80", "0"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		}, {
+			description: "invalid range",
+			opt:         OnlyAllowPorts("9000-8000"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		}, {
+			description: "range too large",
+			opt:         OnlyAllowPorts("8000-65536"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		}, {
+			description: "not numeric",
+			opt:         OnlyAllowPorts("http"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidPortsOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "port allowed",
+			opt:         ForbidPorts("22", "6379"),
+			host:        "http://example.com:8080",
+		}, {
+			description: "port forbidden",
+			opt:         ForbidPorts("22", "6379"),
+			host:        "http://example.com:22",
+			expectedErr: ErrPortNotAllowed,
+		}, {
+			description: "port in range forbidden",
+			opt:         ForbidPorts("1-1023"),
+			host:        "http://example.com:25",
+			expectedErr: ErrPortNotAllowed,
+		}, {
+			description: "default port forbidden",
+			opt:         ForbidPorts("80"),
+			host:        "http://example.com",
+			expectedErr: ErrPortNotAllowed,
+		}, {
+			description: "no known port",
+			opt:         ForbidPorts("1-65535"),
+			noHttp:      true,
+			host:        "gopher://example.com",
+		}, {
+			description: "invalid port",
+			opt:         ForbidPorts("65536"),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidPortsError(t *testing.T) {
+	c, err := New(ForbidPorts("22", "6000-6999"))
+	require.NoError(t, err)
+
+	err = c.Text("http://example.com:6379")
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, StagePort, ve.Stage)
+	assert.Equal(t, "6379", ve.Value)
+	assert.Equal(t, "6000-6999", ve.Pattern)
+	assert.Equal(t, "ForbidPorts('22', '6000-6999')", ve.Option)
+}
+
+func TestOnlyAllowPortsNoKnownPortError(t *testing.T) {
+	c, err := New(OnlyAllowPorts("443"))
+	require.NoError(t, err)
+
+	err = c.Text("gopher://example.com")
+	assert.ErrorIs(t, err, ErrPortNotAllowed)
+	assert.Equal(t, "port not allowed by OnlyAllowPorts('443')", err.Error())
+}
+
+func TestForbidSchemePortMismatchOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "no port",
+			opt:         ForbidSchemePortMismatch(),
+			host:        "https://example.com",
+			noHttp:      true,
+		}, {
+			description: "matching port",
+			opt:         ForbidSchemePortMismatch(),
+			host:        "https://example.com:443",
+			noHttp:      true,
+		}, {
+			description: "port not well known",
+			opt:         ForbidSchemePortMismatch(),
+			host:        "https://example.com:8443",
+			noHttp:      true,
+		}, {
+			description: "shared port",
+			opt:         ForbidSchemePortMismatch(),
+			host:        "ws://example.com:80",
+			noHttp:      true,
+		}, {
+			description: "https on port 80",
+			opt:         ForbidSchemePortMismatch(),
+			host:        "https://example.com:80",
+			noHttp:      true,
+			expectedErr: ErrSchemePortMismatch,
+		}, {
+			description: "http on port 443",
+			opt:         ForbidSchemePortMismatch(),
+			host:        "http://example.com:0443",
+			expectedErr: ErrSchemePortMismatch,
+		}, {
+			description: "unknown scheme on port 21",
+			opt:         ForbidSchemePortMismatch(),
+			host:        "gopher://example.com:21",
+			noHttp:      true,
+			expectedErr: ErrSchemePortMismatch,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestForbidSchemePortMismatchError(t *testing.T) {
+	c, err := New(ForbidSchemePortMismatch())
+	require.NoError(t, err)
+
+	err = c.Text("ftp://example.com:443")
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, StagePort, ve.Stage)
+	assert.Equal(t, "443", ve.Value)
+	assert.Equal(t, "https, wss", ve.Pattern)
+}
+
+func TestPortOptionString(t *testing.T) {
+	opt := OnlyAllowPorts("80", "8000-8999")
+	assert.Equal(t, "OnlyAllowPorts('80', '8000-8999')", opt.String())
+
+	opt = ForbidPorts("22")
+	assert.Equal(t, "ForbidPorts('22')", opt.String())
+
+	opt = ForbidSchemePortMismatch()
+	assert.Equal(t, "ForbidSchemePortMismatch()", opt.String())
+}
+
+func Test_urlPort(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{host: "example.com"},
+		{host: "example.com:"},
+		{host: "example.com:80", want: "80"},
+		{host: "example.com:abc", want: "abc"},
+		{host: "[::1]"},
+		{host: "[::1]:443", want: "443"},
+		{host: "::1"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.host, func(t *testing.T) {
+			assert.Equal(t, tc.want, urlPort(&url.URL{Host: tc.host}))
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// prefixSet is a set of IP prefixes.  The prefixes are aggregated when the
+// set is built, so no two overlap and adjacent sibling prefixes are merged
+// into their parent.  The remaining prefixes are sorted so a lookup is a
+// binary search, independent of the number of prefixes originally provided.
+//
+// The configured prefixes are also kept, grouped by length, so a match can
+// be reported as the subnets that were configured instead of the aggregate.
+// They are only searched once a match has been found.
+type prefixSet struct {
+	prefixes   []netip.Prefix
+	configured []configuredPrefixes
+}
+
+// configuredPrefix is a prefix as it was provided to an Option.
+type configuredPrefix struct {
+	prefix   netip.Prefix
+	original string
+}
+
+// configuredPrefixes are the configured prefixes of one length, sorted by
+// address.
+type configuredPrefixes struct {
+	bits     int
+	prefixes []configuredPrefix
+}
+
+// newPrefixSet builds the aggregated set of the provided prefixes.
+func newPrefixSet(prefixes []configuredPrefix) *prefixSet {
+	sorted := make([]configuredPrefix, 0, len(prefixes))
+	for _, p := range prefixes {
+		p.prefix = unmapPrefix(p.prefix).Masked()
+		sorted = append(sorted, p)
+	}
+
+	// Sorted by length first, so the configured prefixes can be grouped by
+	// length in the same pass.
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].prefix, sorted[j].prefix
+		if a.Bits() != b.Bits() {
+			return a.Bits() < b.Bits()
+		}
+		return a.Addr().Less(b.Addr())
+	})
+
+	set := prefixSet{
+		prefixes: make([]netip.Prefix, 0, len(sorted)),
+	}
+	all := make([]netip.Prefix, 0, len(sorted))
+	for _, p := range sorted {
+		n := len(set.configured)
+		if n == 0 || set.configured[n-1].bits != p.prefix.Bits() {
+			set.configured = append(set.configured, configuredPrefixes{
+				bits: p.prefix.Bits(),
+			})
+			n++
+		}
+		set.configured[n-1].prefixes = append(set.configured[n-1].prefixes, p)
+		all = append(all, p.prefix)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		if c := all[i].Addr().Compare(all[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return all[i].Bits() < all[j].Bits()
+	})
+
+	for _, p := range all {
+		if n := len(set.prefixes); n > 0 && set.prefixes[n-1].Overlaps(p) {
+			// Sorted by address and then by length, so the previous prefix
+			// always covers this one.
+			continue
+		}
+		set.prefixes = append(set.prefixes, p)
+
+		// Merge siblings into their parent, which may in turn have a
+		// sibling of its own.
+		for n := len(set.prefixes); n > 1; n = len(set.prefixes) {
+			parent, ok := siblings(set.prefixes[n-2], set.prefixes[n-1])
+			if !ok {
+				break
+			}
+			set.prefixes = append(set.prefixes[:n-2], parent)
+		}
+	}
+
+	return &set
+}
+
+// siblings returns the parent prefix if a and b are the two halves of it.
+func siblings(a, b netip.Prefix) (netip.Prefix, bool) {
+	if a.Bits() != b.Bits() || a.Bits() == 0 || a.Addr().BitLen() != b.Addr().BitLen() {
+		return netip.Prefix{}, false
+	}
+
+	parent := netip.PrefixFrom(a.Addr(), a.Bits()-1).Masked()
+	if parent != netip.PrefixFrom(b.Addr(), b.Bits()-1).Masked() {
+		return netip.Prefix{}, false
+	}
+	return parent, true
+}
+
+// Contains returns true if a prefix in the set contains the address.  It
+// does not allocate.
+func (s *prefixSet) Contains(addr netip.Addr) bool {
+	addr = addr.Unmap().WithZone("")
+
+	// Find the last prefix that starts at or before the address.  Since the
+	// prefixes do not overlap, it is the only one that can contain it.
+	i := sort.Search(len(s.prefixes), func(i int) bool {
+		return addr.Less(s.prefixes[i].Addr())
+	})
+	return i > 0 && s.prefixes[i-1].Contains(addr)
+}
+
+// Matching returns the configured prefixes that contain the address, in the
+// form they were provided.  They are joined by ", " from the broadest to the
+// most specific.  Each length is a binary search, so the cost depends on the
+// number of distinct lengths, not on the number of prefixes.
+func (s *prefixSet) Matching(addr netip.Addr) string {
+	addr = addr.Unmap().WithZone("")
+
+	var originals []string
+	for _, c := range s.configured {
+		if c.bits > addr.BitLen() {
+			continue
+		}
+		masked, _ := addr.Prefix(c.bits)
+
+		i := sort.Search(len(c.prefixes), func(i int) bool {
+			return !c.prefixes[i].prefix.Addr().Less(masked.Addr())
+		})
+		for ; i < len(c.prefixes) && c.prefixes[i].prefix == masked; i++ {
+			original := c.prefixes[i].original
+			if n := len(originals); n > 0 && originals[n-1] == original {
+				continue
+			}
+			originals = append(originals, original)
+		}
+	}
+	return strings.Join(originals, ", ")
+}
+
+// Len returns the number of prefixes in the set after aggregation.
+func (s *prefixSet) Len() int {
+	return len(s.prefixes)
+}
+
+// parsePrefix parses a CIDR.  IPv4-mapped IPv6 prefixes are treated as the
+// IPv4 prefix they map, the same way net.ParseCIDR treats them.
+func parsePrefix(s string) (netip.Prefix, error) {
+	p, err := netip.ParsePrefix(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w: invalid subnet '%s'", ErrInvalidInput, s)
+	}
+	return unmapPrefix(p).Masked(), nil
+}
+
+// parsePrefixSet parses the CIDRs into an aggregated prefixSet.
+func parsePrefixSet(cidrs []string) (*prefixSet, error) {
+	prefixes := make([]configuredPrefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := parsePrefix(cidr)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, configuredPrefix{
+			prefix:   prefix,
+			original: cidr,
+		})
+	}
+	return newPrefixSet(prefixes), nil
+}
+
+func unmapPrefix(p netip.Prefix) netip.Prefix {
+	if p.Addr().Is4In6() && p.Bits() >= 96 {
+		return netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+	}
+	return p
+}
+
+// addrFromIP converts the net.IP to a netip.Addr, treating IPv4-mapped IPv6
+// addresses as IPv4 addresses.
+func addrFromIP(ip net.IP) (netip.Addr, bool) {
+	addr, ok := netip.AddrFromSlice(ip)
+	return addr.Unmap(), ok
+}
+
+// addrsFromIPs converts the net.IPs to netip.Addrs, dropping any that are
+// not valid.
+func addrsFromIPs(ips []net.IP) []netip.Addr {
+	rv := make([]netip.Addr, 0, len(ips))
+	for _, ip := range ips {
+		if addr, ok := addrFromIP(ip); ok {
+			rv = append(rv, addr)
+		}
+	}
+	return rv
+}
+
+// ipFromAddr converts the netip.Addr to a net.IP in the form net.ParseIP
+// returns.  The zone is not preserved.
+func ipFromAddr(addr netip.Addr) net.IP {
+	if addr.Is4() {
+		b := addr.As4()
+		return net.IPv4(b[0], b[1], b[2], b[3])
+	}
+	return net.IP(addr.AsSlice())
+}
+
+// ipsFromAddrs converts the netip.Addrs to net.IPs.
+func ipsFromAddrs(addrs []netip.Addr) []net.IP {
+	rv := make([]net.IP, 0, len(addrs))
+	for _, addr := range addrs {
+		rv = append(rv, ipFromAddr(addr))
+	}
+	return rv
+}
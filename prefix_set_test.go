@@ -0,0 +1,224 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mustPrefixes(t *testing.T, cidrs ...string) []configuredPrefix {
+	rv := make([]configuredPrefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		p, err := parsePrefix(cidr)
+		require.NoError(t, err)
+		rv = append(rv, configuredPrefix{prefix: p, original: cidr})
+	}
+	return rv
+}
+
+func Test_newPrefixSet(t *testing.T) {
+	tests := []struct {
+		description string
+		cidrs       []string
+		expected    []string
+	}{
+		{
+			description: "empty",
+		}, {
+			description: "nested",
+			cidrs:       []string{"10.0.0.0/24", "10.0.0.0/8", "10.1.2.3/32"},
+			expected:    []string{"10.0.0.0/8"},
+		}, {
+			description: "adjacent siblings",
+			cidrs:       []string{"10.0.0.128/25", "10.0.0.0/25"},
+			expected:    []string{"10.0.0.0/24"},
+		}, {
+			description: "cascading siblings",
+			cidrs:       []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/25", "10.0.1.0/24"},
+			expected:    []string{"10.0.0.0/23"},
+		}, {
+			description: "adjacent but not siblings",
+			cidrs:       []string{"10.0.1.0/24", "10.0.2.0/24"},
+			expected:    []string{"10.0.1.0/24", "10.0.2.0/24"},
+		}, {
+			description: "duplicates",
+			cidrs:       []string{"192.168.0.0/16", "192.168.0.0/16"},
+			expected:    []string{"192.168.0.0/16"},
+		}, {
+			description: "mixed families",
+			cidrs:       []string{"::/1", "0.0.0.0/1", "128.0.0.0/1", "8000::/1"},
+			expected:    []string{"0.0.0.0/0", "::/0"},
+		}, {
+			description: "ipv4 mapped",
+			cidrs:       []string{"::ffff:10.0.0.0/104"},
+			expected:    []string{"10.0.0.0/8"},
+		}, {
+			description: "unmasked",
+			cidrs:       []string{"10.1.2.3/8"},
+			expected:    []string{"10.0.0.0/8"},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			set := newPrefixSet(mustPrefixes(t, tc.cidrs...))
+
+			got := make([]string, 0, set.Len())
+			for _, p := range set.prefixes {
+				got = append(got, p.String())
+			}
+			assert.ElementsMatch(t, tc.expected, got)
+		})
+	}
+}
+
+func Test_prefixSetContains(t *testing.T) {
+	set := newPrefixSet(mustPrefixes(t,
+		"10.0.0.0/8",
+		"10.1.0.0/16",
+		"172.16.0.0/12",
+		"192.168.1.0/25",
+		"192.168.1.128/25",
+		"fd00::/8",
+	))
+
+	tests := []struct {
+		addr     string
+		expected string
+	}{
+		{addr: "10.0.2.3", expected: "10.0.0.0/8"},
+		{addr: "10.1.2.3", expected: "10.0.0.0/8, 10.1.0.0/16"},
+		{addr: "9.255.255.255"},
+		{addr: "11.0.0.0"},
+		{addr: "172.31.255.255", expected: "172.16.0.0/12"},
+		{addr: "192.168.1.1", expected: "192.168.1.0/25"},
+		{addr: "192.168.1.200", expected: "192.168.1.128/25"},
+		{addr: "192.168.2.1"},
+		{addr: "::ffff:10.0.0.1", expected: "10.0.0.0/8"},
+		{addr: "fd12::1", expected: "fd00::/8"},
+		{addr: "fd12::1%eth0", expected: "fd00::/8"},
+		{addr: "fe80::1"},
+		{addr: "0.0.0.0"},
+		{addr: "::"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.addr, func(t *testing.T) {
+			addr := netip.MustParseAddr(tc.addr)
+			if tc.expected == "" {
+				assert.False(t, set.Contains(addr))
+				assert.Empty(t, set.Matching(addr))
+				return
+			}
+			require.True(t, set.Contains(addr))
+			assert.Equal(t, tc.expected, set.Matching(addr))
+		})
+	}
+}
+
+// Test_prefixSetLarge checks that a large set agrees with a linear search.
+func Test_prefixSetLarge(t *testing.T) {
+	cidrs := make([]string, 0, 10000)
+	nets := make([]*net.IPNet, 0, 10000)
+	for i := 0; i < 10000; i++ {
+		// Spread the subnets out, with some overlap and some adjacency.  The
+		// lengths increase with i, so the matches of a linear search are
+		// already ordered from the broadest to the most specific.
+		cidr := fmt.Sprintf("%d.%d.%d.0/%d", 1+i%200, (i*7)%256, (i*13)%256, 20+i/2000)
+		_, n, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		cidrs = append(cidrs, cidr)
+		nets = append(nets, n)
+	}
+
+	set := newPrefixSet(mustPrefixes(t, cidrs...))
+	assert.Less(t, set.Len(), len(cidrs))
+
+	for i := 0; i < 2000; i++ {
+		ip := net.IPv4(byte(i%203), byte(i*31), byte(i*17), byte(i*3))
+
+		var expected []string
+		for j, n := range nets {
+			if n.Contains(ip) && (len(expected) == 0 || expected[len(expected)-1] != cidrs[j]) {
+				expected = append(expected, cidrs[j])
+			}
+		}
+
+		addr, ok := addrFromIP(ip)
+		require.True(t, ok)
+		require.Equal(t, len(expected) > 0, set.Contains(addr), ip.String())
+		require.Equal(t, strings.Join(expected, ", "), set.Matching(addr), ip.String())
+	}
+}
+
+// mergedPrefixes returns every /24 in 10.0.0.0/8, which aggregate into a
+// single prefix.
+func mergedPrefixes(t testing.TB) []configuredPrefix {
+	rv := make([]configuredPrefix, 0, 1<<16)
+	for i := 0; i < 1<<16; i++ {
+		cidr := fmt.Sprintf("10.%d.%d.0/24", i>>8, i&0xff)
+		p, err := parsePrefix(cidr)
+		require.NoError(t, err)
+		rv = append(rv, configuredPrefix{prefix: p, original: cidr})
+	}
+	return rv
+}
+
+func Test_prefixSetMerged(t *testing.T) {
+	set := newPrefixSet(mergedPrefixes(t))
+	require.Equal(t, 1, set.Len())
+
+	hit := netip.MustParseAddr("10.200.3.4")
+	miss := netip.MustParseAddr("11.0.0.1")
+
+	assert.True(t, set.Contains(hit))
+	assert.False(t, set.Contains(miss))
+	assert.Equal(t, "10.200.3.0/24", set.Matching(hit))
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		set.Contains(hit)
+		set.Contains(miss)
+	}))
+
+	rule := onlyAllowSubnets(set)
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = rule(hit)
+	}))
+}
+
+func Benchmark_prefixSetMerged(b *testing.B) {
+	set := newPrefixSet(mergedPrefixes(b))
+	hit := netip.MustParseAddr("10.200.3.4")
+
+	b.Run("Contains", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			set.Contains(hit)
+		}
+	})
+	b.Run("Matching", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			set.Matching(hit)
+		}
+	})
+}
+
+func Test_parsePrefix(t *testing.T) {
+	_, err := parsePrefix("10.0.0.0/33")
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+
+	_, err = parsePrefix("10.0.0.0")
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+
+	p, err := parsePrefix("::ffff:192.168.0.0/112")
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.0.0/16", p.String())
+}
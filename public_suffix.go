@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// ForbidPublicSuffixes returns an Option that disallows hostnames that are a
+// bare public suffix, such as "co.uk" or "github.io", or a top level domain,
+// such as "com".  Single label hostnames are treated as top level domains.
+// The Public Suffix List embedded in golang.org/x/net/publicsuffix is used.
+//
+// The error returned is ErrRootDomainNotAllowed.
+//
+// See https://publicsuffix.org/
+func ForbidPublicSuffixes() Option {
+	return forbidPublicSuffixesOption{}
+}
+
+type forbidPublicSuffixesOption struct{}
+
+func (forbidPublicSuffixesOption) String() string {
+	return "ForbidPublicSuffixes()"
+}
+
+func (forbidPublicSuffixesOption) apply(c *Checker) {
+	c.hostRules = append(c.hostRules, HostVador(forbidPublicSuffixes).withContext())
+}
+
+func forbidPublicSuffixes(host string) error {
+	host = strings.TrimSuffix(host, ".")
+	if suffix, _ := publicsuffix.PublicSuffix(host); suffix == host {
+		return matched(ErrRootDomainNotAllowed, suffix)
+	}
+	return nil
+}
+
+// RegistrableDomain returns the registrable domain of the hostname, also
+// known as the eTLD+1: the public suffix plus one more label.  For example,
+// "www.example.co.uk" returns "example.co.uk" and "user.github.io" returns
+// "user.github.io".  This is useful for grouping hostnames by the entity
+// that registered them.
+//
+// The hostname is normalized the same way a Checker normalizes hostnames.
+// If the hostname is itself a public suffix, ErrRootDomainNotAllowed is
+// returned.  If the hostname is an IP address or is not a valid hostname,
+// ErrInvalidInput is returned.
+func RegistrableDomain(host string) (string, error) {
+	ascii, err := toASCII(strings.TrimSuffix(host, "."))
+	if _, ipErr := netip.ParseAddr(ascii); err != nil || ascii == "" || ipErr == nil {
+		return "", fmt.Errorf("%w: invalid hostname '%s'", ErrInvalidInput, host)
+	}
+
+	if _, err := hostnameProcess(ascii); err != nil {
+		return "", err
+	}
+
+	if err := forbidPublicSuffixes(ascii); err != nil {
+		return "", fmt.Errorf("%w: '%s' is a public suffix", ErrRootDomainNotAllowed, host)
+	}
+
+	domain, err := publicsuffix.EffectiveTLDPlusOne(ascii)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid hostname '%s'", ErrInvalidInput, host)
+	}
+	return domain, nil
+}
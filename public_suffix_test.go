@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForbidPublicSuffixesOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "registrable domains",
+			opt:         ForbidPublicSuffixes(),
+			hosts: []string{
+				"http://example.com",
+				"http://www.example.co.uk",
+				"http://user.github.io",
+				"http://example.com.",
+				"http://127.0.0.1",
+			},
+		}, {
+			description: "public suffixes",
+			opt:         ForbidPublicSuffixes(),
+			hosts: []string{
+				"http://com",
+				"http://co.uk",
+				"http://github.io",
+				"http://CO.UK.",
+				"http://localhost",
+				"http://unknowntld",
+			},
+			expectedErr: ErrRootDomainNotAllowed,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestRegistrableDomain(t *testing.T) {
+	tests := []struct {
+		host        string
+		want        string
+		expectedErr error
+	}{
+		{host: "example.com", want: "example.com"},
+		{host: "www.Example.com.", want: "example.com"},
+		{host: "a.b.example.co.uk", want: "example.co.uk"},
+		{host: "user.github.io", want: "user.github.io"},
+		{host: "x.user.github.io", want: "user.github.io"},
+		{host: "www.bücher.de", want: "xn--bcher-kva.de"},
+		{host: "com", expectedErr: ErrRootDomainNotAllowed},
+		{host: "co.uk", expectedErr: ErrRootDomainNotAllowed},
+		{host: "github.io", expectedErr: ErrRootDomainNotAllowed},
+		{host: "", expectedErr: ErrInvalidInput},
+		{host: "127.0.0.1", expectedErr: ErrInvalidInput},
+		{host: "::1", expectedErr: ErrInvalidInput},
+		{host: "www..example.com", expectedErr: ErrInvalidInput},
+	}
+	for _, tc := range tests {
+		t.Run(tc.host, func(t *testing.T) {
+			got, err := RegistrableDomain(tc.host)
+
+			assert.ErrorIs(t, err, tc.expectedErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestForbidPublicSuffixesOptionString(t *testing.T) {
+	opt := ForbidPublicSuffixes()
+	assert.Equal(t, "ForbidPublicSuffixes()", opt.String())
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// MaxQueryParams returns an Option that disallows queries with more than n
+// parameters.  Every non-empty '&' separated part of the raw query is
+// counted, including repeated keys and parts that cannot be parsed.  If n is
+// negative then the Option will return an error.
+func MaxQueryParams(n int) Option {
+	if n < 0 {
+		return Error(fmt.Errorf("%w: negative max query parameters %d", ErrInvalidInput, n))
+	}
+	return maxQueryParamsOption{max: n}
+}
+
+type maxQueryParamsOption struct {
+	max int
+}
+
+func (o maxQueryParamsOption) String() string {
+	return "MaxQueryParams(" + strconv.Itoa(o.max) + ")"
+}
+
+func (o maxQueryParamsOption) apply(c *Checker) {
+	c.queryRules = append(c.queryRules, maxQueryParams(o.max))
+}
+
+func maxQueryParams(max int) queryRule {
+	return func(rawQuery string) error {
+		count := 0
+		for _, part := range strings.Split(rawQuery, "&") {
+			if part != "" {
+				count++
+			}
+		}
+
+		if count > max {
+			return ErrTooManyQueryParams
+		}
+		return nil
+	}
+}
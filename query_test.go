@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxQueryParamsOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "no query",
+			opt:         MaxQueryParams(0),
+			host:        "http://example.com/",
+		}, {
+			description: "empty query",
+			opt:         MaxQueryParams(0),
+			host:        "http://example.com/?",
+		}, {
+			description: "under the limit",
+			opt:         MaxQueryParams(2),
+			host:        "http://example.com/?a=1&b=2",
+		}, {
+			description: "empty parts are not counted",
+			opt:         MaxQueryParams(2),
+			host:        "http://example.com/?a=1&&b=2&",
+		}, {
+			description: "over the limit",
+			opt:         MaxQueryParams(2),
+			host:        "http://example.com/?a=1&b=2&c=3",
+			expectedErr: ErrTooManyQueryParams,
+		}, {
+			description: "repeated keys are counted",
+			opt:         MaxQueryParams(2),
+			host:        "http://example.com/?a=1&a=2&a=3",
+			expectedErr: ErrTooManyQueryParams,
+		}, {
+			description: "unparsable parts are counted",
+			opt:         MaxQueryParams(2),
+			host:        "http://example.com/?a=1&b=2&c=%zz",
+			expectedErr: ErrTooManyQueryParams,
+		}, {
+			description: "negative",
+			opt:         MaxQueryParams(-1),
+			failOnNew:   true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	testCommon(t, tests)
+}
+
+func TestMaxQueryParamsError(t *testing.T) {
+	c, err := New(MaxQueryParams(1))
+	require.NoError(t, err)
+
+	err = c.Text("http://example.com/?user=alice&token=secret")
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, StageQuery, ve.Stage)
+	assert.Empty(t, ve.Value)
+	assert.NotContains(t, err.Error(), "alice")
+	assert.NotContains(t, err.Error(), "secret")
+}
+
+func TestMaxQueryParamsOptionString(t *testing.T) {
+	opt := MaxQueryParams(10)
+	assert.Equal(t, "MaxQueryParams(10)", opt.String())
+}
@@ -0,0 +1,73 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxRedirects matches the limit used by the net/http package.
+const defaultMaxRedirects = 10
+
+// MaxRedirects returns an Option that sets the maximum number of redirects
+// CheckRedirect will follow.  The default is 10.  Zero prevents any redirect
+// from being followed.  If the count is negative then the Option will return
+// an error.
+func MaxRedirects(n int) Option {
+	if n < 0 {
+		return Error(fmt.Errorf("%w: invalid max redirects %d", ErrInvalidInput, n))
+	}
+	return maxRedirectsOption{n: n}
+}
+
+type maxRedirectsOption struct {
+	n int
+}
+
+func (o maxRedirectsOption) String() string {
+	return "MaxRedirects(" + strconv.Itoa(o.n) + ")"
+}
+
+func (o maxRedirectsOption) apply(c *Checker) {
+	c.maxRedirects = o.n
+}
+
+// insecureSchemes maps each secure scheme to the insecure scheme that a
+// redirect must not downgrade it to.
+var insecureSchemes = map[string]string{
+	"https": "http",
+	"wss":   "ws",
+}
+
+// CheckRedirect validates each redirect hop and can be used as
+// http.Client.CheckRedirect.  Every hop is checked with the full set of
+// rules, the number of hops is limited (see MaxRedirects), and a downgrade
+// from a secure scheme to its insecure form, such as https to http or wss to
+// ws, is rejected.
+func (c *Checker) CheckRedirect(req *http.Request, via []*http.Request) error {
+	if req == nil {
+		return ErrInvalidInput
+	}
+
+	if len(via) >= c.maxRedirects {
+		return fmt.Errorf("%w: stopped after %d redirects", ErrTooManyRedirects, c.maxRedirects)
+	}
+
+	if len(via) > 0 && req.URL != nil {
+		prev := strings.ToLower(via[len(via)-1].URL.Scheme)
+		next := strings.ToLower(req.URL.Scheme)
+		if insecure, ok := insecureSchemes[prev]; ok && next == insecure {
+			return &ValidationError{
+				Stage: StageScheme,
+				Value: next,
+				Err:   ErrSchemeDowngrade,
+			}
+		}
+	}
+
+	return c.URLContext(req.Context(), req.URL)
+}
@@ -0,0 +1,128 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckRedirect(t *testing.T) {
+	tests := []struct {
+		description string
+		opts        []Option
+		url         string
+		via         []string
+		nilReq      bool
+		expectedErr error
+	}{
+		{
+			description: "first hop allowed",
+			opts:        []Option{OnlyAllowSchemes("http", "https")},
+			url:         "https://example.com",
+			via:         []string{"https://example.org"},
+		}, {
+			description: "upgrade allowed",
+			url:         "https://example.com",
+			via:         []string{"http://example.org"},
+		}, {
+			description: "hop rejected by the rules",
+			opts:        []Option{ForbidLoopback()},
+			url:         "http://127.0.0.1/",
+			via:         []string{"http://example.org"},
+			expectedErr: ErrLoopback,
+		}, {
+			description: "downgrade rejected",
+			url:         "http://example.com",
+			via:         []string{"http://example.net", "HTTPS://example.org"},
+			expectedErr: ErrSchemeDowngrade,
+		}, {
+			description: "websocket downgrade rejected",
+			opts:        []Option{OnlyAllowSchemes("ws", "wss")},
+			url:         "ws://example.com",
+			via:         []string{"WSS://example.org"},
+			expectedErr: ErrSchemeDowngrade,
+		}, {
+			description: "websocket upgrade allowed",
+			opts:        []Option{OnlyAllowSchemes("ws", "wss")},
+			url:         "wss://example.com",
+			via:         []string{"ws://example.org"},
+		}, {
+			description: "too many redirects",
+			opts:        []Option{MaxRedirects(2)},
+			url:         "http://example.com",
+			via:         []string{"http://example.net", "http://example.org"},
+			expectedErr: ErrTooManyRedirects,
+		}, {
+			description: "no redirects",
+			opts:        []Option{MaxRedirects(0)},
+			url:         "http://example.com",
+			via:         []string{"http://example.net"},
+			expectedErr: ErrTooManyRedirects,
+		}, {
+			description: "default limit",
+			url:         "http://example.com",
+			via:         make([]string, 10),
+			expectedErr: ErrTooManyRedirects,
+		}, {
+			description: "nil request",
+			nilReq:      true,
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			require := require.New(t)
+
+			var req *http.Request
+			if !tc.nilReq {
+				var err error
+				req, err = http.NewRequest(http.MethodGet, tc.url, nil)
+				require.NoError(err)
+			}
+
+			via := make([]*http.Request, 0, len(tc.via))
+			for _, v := range tc.via {
+				r, err := http.NewRequest(http.MethodGet, v, nil)
+				require.NoError(err)
+				via = append(via, r)
+			}
+
+			c := Must(tc.opts...)
+			err := c.CheckRedirect(req, via)
+			assert.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestCheckRedirectClient(t *testing.T) {
+	assert := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	c := Must(ForbidSubnet("169.254.0.0/16"))
+	client := http.Client{
+		CheckRedirect: c.CheckRedirect,
+	}
+
+	resp, err := client.Get(server.URL)
+	assert.ErrorIs(err, ErrSubnetNotAllowed)
+	if resp != nil {
+		resp.Body.Close()
+	}
+}
+
+func TestMaxRedirectsOption(t *testing.T) {
+	_, err := New(MaxRedirects(-1))
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	assert.Equal(t, "MaxRedirects(3)", MaxRedirects(3).String())
+}
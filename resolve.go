@@ -0,0 +1,88 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// defaultPorts are the well known ports for the schemes commonly validated.
+// The WHATWG parser treats these schemes as special, see specialSchemes.
+var defaultPorts = map[string]string{
+	"ftp":   "21",
+	"http":  "80",
+	"https": "443",
+	"ws":    "80",
+	"wss":   "443",
+}
+
+// Resolved is the validated destination of a URL.  Dialing only the
+// addresses in a Resolved avoids a second lookup, which could return
+// different IPs than the ones that were checked.
+type Resolved struct {
+	// Host is the hostname or IP literal from the URL, normalized the way the
+	// rules checked it.  Internationalized hostnames are in their ASCII
+	// form, and IP literals are in their standard form.
+	Host string
+
+	// Port is the port from the URL, or the well known port of the scheme
+	// if the URL does not specify one.  The port is empty if neither is
+	// known.
+	Port string
+
+	// IPs are the addresses that passed every IP rule.
+	IPs []net.IP
+}
+
+// Addrs returns the "host:port" form of each of the IPs, suitable for
+// passing to net.Dial.
+func (r *Resolved) Addrs() []string {
+	rv := make([]string, 0, len(r.IPs))
+	for _, ip := range r.IPs {
+		rv = append(rv, net.JoinHostPort(ip.String(), r.Port))
+	}
+	return rv
+}
+
+// Resolve validates the URL and returns the addresses it resolved to.  The
+// hostname is resolved with the configured resolver, or net.DefaultResolver
+// if none is configured, and every rule is applied the same way URL applies
+// them.  If any rule fails, the error is returned instead.  If the hostname
+// did not resolve to any addresses, ErrNoAddress is returned.
+func (c *Checker) Resolve(u *url.URL) (*Resolved, error) {
+	return c.ResolveContext(context.Background(), u)
+}
+
+// ResolveContext is the same as Resolve, but the context applies to any
+// lookups performed.
+func (c *Checker) ResolveContext(ctx context.Context, u *url.URL) (*Resolved, error) {
+	resolver := c.resolver
+	if resolver == nil {
+		resolver = defaultResolver("ip")
+	}
+
+	var r run
+	host, ips := c.check(ctx, u, resolver, &r)
+	if err := r.err(); err != nil {
+		return nil, err
+	}
+	if len(ips) == 0 {
+		return nil, fmt.Errorf("%w: '%s'", ErrNoAddress, u.Hostname())
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = defaultPorts[strings.ToLower(u.Scheme)]
+	}
+
+	return &Resolved{
+		Host: host,
+		Port: port,
+		IPs:  ipsFromAddrs(ips),
+	}, nil
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockEmptyResolver(context.Context, string) ([]net.IP, error) {
+	return nil, nil
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		description string
+		opts        []Option
+		url         string
+		expected    *Resolved
+		addrs       []string
+		expectedErr error
+	}{
+		{
+			description: "ip literal with a port",
+			url:         "http://127.0.0.1:8080/path",
+			expected: &Resolved{
+				Host: "127.0.0.1",
+				Port: "8080",
+				IPs:  []net.IP{net.ParseIP("127.0.0.1")},
+			},
+			addrs: []string{"127.0.0.1:8080"},
+		}, {
+			description: "ipv6 literal with the default port",
+			url:         "https://[::1]/path",
+			expected: &Resolved{
+				Host: "::1",
+				Port: "443",
+				IPs:  []net.IP{net.ParseIP("::1")},
+			},
+			addrs: []string{"[::1]:443"},
+		}, {
+			description: "resolved hostname",
+			opts:        []Option{ForbidSubnet("10.0.0.0/8"), WithResolver(mockResolver)},
+			url:         "HTTP://Mock-Private-Loopback.com",
+			expected: &Resolved{
+				Host: "mock-private-loopback.com",
+				Port: "80",
+				IPs:  []net.IP{net.ParseIP("192.168.1.1"), net.ParseIP("127.0.0.1")},
+			},
+			addrs: []string{"192.168.1.1:80", "127.0.0.1:80"},
+		}, {
+			description: "internationalized hostname",
+			opts:        []Option{WithResolverContext(mockSliceResolver("192.0.2.1"))},
+			url:         "http://BÜCHER.de",
+			expected: &Resolved{
+				Host: "xn--bcher-kva.de",
+				Port: "80",
+				IPs:  []net.IP{net.ParseIP("192.0.2.1")},
+			},
+		}, {
+			description: "legacy ipv4 literal",
+			url:         "http://0x08.8.8.8/",
+			expected: &Resolved{
+				Host: "8.8.8.8",
+				Port: "80",
+				IPs:  []net.IP{net.ParseIP("8.8.8.8")},
+			},
+			addrs: []string{"8.8.8.8:80"},
+		}, {
+			description: "unknown scheme has no port",
+			url:         "gopher://127.0.0.1",
+			expected: &Resolved{
+				Host: "127.0.0.1",
+				IPs:  []net.IP{net.ParseIP("127.0.0.1")},
+			},
+		}, {
+			description: "resolved ip rejected",
+			opts:        []Option{ForbidLoopback(), WithResolver(mockResolver)},
+			url:         mockPrivateLoopbackURL,
+			expectedErr: ErrLoopback,
+		}, {
+			description: "no addresses",
+			opts:        []Option{WithResolverContext(mockEmptyResolver)},
+			url:         "http://example.com",
+			expectedErr: ErrNoAddress,
+		}, {
+			description: "nil url",
+			expectedErr: ErrInvalidInput,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var u *url.URL
+			if tc.url != "" {
+				var err error
+				u, err = url.Parse(tc.url)
+				require.NoError(err)
+			}
+
+			c := Must(tc.opts...)
+			got, err := c.Resolve(u)
+
+			assert.ErrorIs(err, tc.expectedErr)
+			if tc.expectedErr != nil {
+				assert.Nil(got)
+				return
+			}
+
+			require.NotNil(got)
+			assert.Equal(tc.expected.Host, got.Host)
+			assert.Equal(tc.expected.Port, got.Port)
+			require.Len(got.IPs, len(tc.expected.IPs))
+			for i := range tc.expected.IPs {
+				assert.True(tc.expected.IPs[i].Equal(got.IPs[i]))
+			}
+			if tc.addrs != nil {
+				assert.Equal(tc.addrs, got.Addrs())
+			}
+		})
+	}
+}
@@ -3,6 +3,14 @@
 
 package urlegit
 
+import (
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"net/netip"
+	"strings"
+)
+
 // ForbidSpecialUseDomains returns an Option that disallows the use of special
 // use domains.  See https://www.iana.org/assignments/special-use-domain-names/special-use-domain-names.xhtml
 func ForbidSpecialUseDomains() Option {
@@ -16,3 +24,130 @@ func ForbidSpecialUseDomains() Option {
 		"example.*",
 	)
 }
+
+var (
+	// The IANA special-purpose address registries in their CSV form.
+	//
+	// See:
+	//   - https://www.iana.org/assignments/iana-ipv4-special-registry/iana-ipv4-special-registry.xhtml
+	//   - https://www.iana.org/assignments/iana-ipv6-special-registry/iana-ipv6-special-registry.xhtml
+	//
+	// Snapshot taken 2026-10-18.  When refreshing, compare against the
+	// "Last Updated" date of each registry and update this date.
+
+	//go:embed iana-ipv4-special-registry.csv
+	ipv4SpecialRegistry string
+
+	//go:embed iana-ipv6-special-registry.csv
+	ipv6SpecialRegistry string
+)
+
+// ForbidSpecialUseIPs returns an Option that disallows the addresses that the
+// IANA special-purpose address registries mark as not globally reachable,
+// such as 0.0.0.0/8, 192.0.0.0/24, 198.18.0.0/15, 240.0.0.0/4 and
+// 2001:db8::/32.  As the registries specify, the most specific entry that
+// contains an address decides if it is allowed.  The rule applies to IP
+// literals and to resolved IPs when a resolver is provided.
+//
+// The error returned is ErrSubnetNotAllowed, and the pattern names the
+// registry entry that matched.
+func ForbidSpecialUseIPs() Option {
+	entries, err := parseSpecialRegistry(ipv4SpecialRegistry)
+	if err != nil {
+		return Error(err)
+	}
+
+	v6, err := parseSpecialRegistry(ipv6SpecialRegistry)
+	if err != nil {
+		return Error(err)
+	}
+
+	return forbidSpecialUseIPsOption{
+		entries: append(entries, v6...),
+	}
+}
+
+type forbidSpecialUseIPsOption struct {
+	entries []specialUseEntry
+}
+
+func (forbidSpecialUseIPsOption) String() string {
+	return "ForbidSpecialUseIPs()"
+}
+
+func (o forbidSpecialUseIPsOption) apply(c *Checker) {
+	c.ipRules = append(c.ipRules, forbidSpecialUseIPs(o.entries))
+}
+
+func forbidSpecialUseIPs(entries []specialUseEntry) AddrVador {
+	return func(addr netip.Addr) error {
+		addr = addr.WithZone("")
+
+		var best *specialUseEntry
+		for i := range entries {
+			bits := entries[i].subnet.Bits()
+			if (best == nil || bits > best.subnet.Bits()) && entries[i].subnet.Contains(addr) {
+				best = &entries[i]
+			}
+		}
+
+		if best == nil || best.reachable {
+			return nil
+		}
+
+		return matched(ErrSubnetNotAllowed, best.subnet.String()+" ("+best.name+")")
+	}
+}
+
+// specialUseEntry is a single entry in a special-purpose address registry.
+type specialUseEntry struct {
+	name      string
+	subnet    netip.Prefix
+	reachable bool
+}
+
+// parseSpecialRegistry parses the CSV form of an IANA special-purpose
+// address registry.  Entries without a globally reachable value have been
+// deprecated and are skipped.
+func parseSpecialRegistry(s string) ([]specialUseEntry, error) {
+	const (
+		addressBlock = iota
+		name
+		globallyReachable = 8
+	)
+
+	records, err := csv.NewReader(strings.NewReader(s)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]specialUseEntry, 0, len(records))
+	for _, record := range records[1:] {
+		reachable := strings.TrimSpace(record[globallyReachable])
+		if reachable == "" {
+			continue
+		}
+
+		block := strings.TrimSpace(record[addressBlock])
+		subnet, err := netip.ParsePrefix(block)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid registry entry '%s'", ErrInvalidInput, block)
+		}
+		subnet = subnet.Masked()
+
+		// Mapped addresses are checked against the IPv4 registry instead.
+		if subnet.Addr().Is4In6() {
+			continue
+		}
+
+		entries = append(entries, specialUseEntry{
+			name:   strings.Trim(record[name], `"`),
+			subnet: subnet,
+			// Anything other than an explicit False is treated as
+			// reachable, including N/A.
+			reachable: reachable != "False",
+		})
+	}
+
+	return entries, nil
+}
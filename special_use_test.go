@@ -4,7 +4,11 @@
 package urlegit
 
 import (
+	"errors"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestForbidSpecialUseDomainsOption(t *testing.T) {
@@ -28,3 +32,75 @@ func TestForbidSpecialUseDomainsOption(t *testing.T) {
 
 	testCommon(t, tests)
 }
+
+func TestForbidSpecialUseIPsOption(t *testing.T) {
+	tests := []sharedTest{
+		{
+			description: "globally reachable",
+			opt:         ForbidSpecialUseIPs(),
+			hosts: []string{
+				"http://8.8.8.8",
+				"http://192.0.0.9",
+				"http://192.31.196.1",
+				"http://192.88.99.1",
+				"http://[2001:4860:4860::8888]",
+				"http://[2001:1::1]",
+				"http://[2001:20::1]",
+				"http://[64:ff9b::808:808]",
+				"http://example.com",
+			},
+		}, {
+			description: "not globally reachable",
+			opt:         ForbidSpecialUseIPs(),
+			hosts: []string{
+				"http://0.1.2.3",
+				"http://10.0.0.1",
+				"http://127.0.0.1",
+				"http://192.0.0.1",
+				"http://192.0.0.255",
+				"http://192.0.2.1",
+				"http://198.19.0.1",
+				"http://240.0.0.1",
+				"http://255.255.255.255",
+				"http://[::]",
+				"http://[::1]",
+				"http://[::ffff:127.0.0.1]",
+				"http://[2001:db8::1]",
+				"http://[2001:2::1]",
+				"http://[fd00::1]",
+				"http://[fe80::1]",
+			},
+			expectedErr: ErrSubnetNotAllowed,
+		}, {
+			description: "resolved address",
+			opt:         ForbidSpecialUseIPs(),
+			opts:        []Option{WithResolver(mockResolver)},
+			host:        mockPrivateURL,
+			expectedErr: ErrSubnetNotAllowed,
+		},
+	}
+
+	testCommon(t, tests)
+}
+
+func TestForbidSpecialUseIPsError(t *testing.T) {
+	err := Must(ForbidSpecialUseIPs()).Text("http://198.18.0.1")
+
+	var ve *ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "198.18.0.0/15 (Benchmarking)", ve.Pattern)
+	assert.Equal(t, "ForbidSpecialUseIPs()", ve.Option)
+}
+
+func TestForbidSpecialUseIPsOptionString(t *testing.T) {
+	opt := ForbidSpecialUseIPs()
+	assert.Equal(t, "ForbidSpecialUseIPs()", opt.String())
+}
+
+func Test_parseSpecialRegistry(t *testing.T) {
+	_, err := parseSpecialRegistry("header\nunterminated \"quote")
+	assert.Error(t, err)
+
+	_, err = parseSpecialRegistry("a,b,c,d,e,f,g,h,i,j\n1.2.3/33,x,,,,,,,False,\n")
+	assert.ErrorIs(t, err, ErrInvalidInput)
+}
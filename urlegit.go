@@ -4,8 +4,11 @@
 package urlegit
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net"
+	"net/netip"
 	"net/url"
 	"strings"
 )
@@ -19,15 +22,40 @@ var (
 	ErrInvalidInput         = fmt.Errorf("invalid input")
 	ErrSubnetNotAllowed     = fmt.Errorf("subnet not allowed")
 	ErrIPNotAllowed         = fmt.Errorf("IPs not allowed")
+	ErrNoAddress            = fmt.Errorf("no allowed address")
+	ErrTooManyRedirects     = fmt.Errorf("too many redirects")
+	ErrSchemeDowngrade      = fmt.Errorf("scheme downgrade not allowed")
+	ErrLegacyIPv4           = fmt.Errorf("legacy IPv4 notation not allowed")
+	ErrInvalidIDN           = fmt.Errorf("invalid internationalized domain name")
+	ErrConfusableHost       = fmt.Errorf("confusable hostname")
+	ErrInvalidPort          = fmt.Errorf("invalid port")
+	ErrPortNotAllowed       = fmt.Errorf("port not allowed")
+	ErrSchemePortMismatch   = fmt.Errorf("port does not match scheme")
+	ErrUserinfoNotAllowed   = fmt.Errorf("userinfo not allowed")
+	ErrPathNotAllowed       = fmt.Errorf("path not allowed")
+	ErrPathTraversal        = fmt.Errorf("path traversal not allowed")
+	ErrTooManyQueryParams   = fmt.Errorf("too many query parameters")
+	ErrFragmentNotAllowed   = fmt.Errorf("fragment not allowed")
 )
 
 // Checker is a URL validator.
 type Checker struct {
 	schemeRules   []SchemeVador
-	ipBeforeRules []IPVador
-	resolver      Resolver
-	hostRules     []HostVador
-	ipRules       []IPVador
+	userRules     []userRule
+	portRules     []portRule
+	pathRules     []PathVador
+	queryRules    []queryRule
+	fragmentRules []fragmentRule
+	ipBeforeRules []AddrVador
+	resolver      ResolverContext
+	hostRules     []hostRule
+	ipRules       []AddrVador
+	ipAllowRules  []AddrVador
+	maxRedirects  int
+	embeddedIPv4  bool
+	legacyIPv4    Option
+	invalidIDN    Option
+	parser        Parser
 	err           error
 	opts          []Option
 }
@@ -44,18 +72,74 @@ type SchemeVador func(string) error
 // IPVador is a function that validates an IP.
 type IPVador func(*net.IP) error
 
+// AddrVador is a function that validates an IP address.  IPv4 and
+// IPv4-mapped IPv6 addresses are both provided as IPv4 addresses, and the
+// zone of an IPv6 address is preserved.
+type AddrVador func(netip.Addr) error
+
+// withAddr adapts an IPVador into an AddrVador.
+func (v IPVador) withAddr() AddrVador {
+	return func(addr netip.Addr) error {
+		ip := ipFromAddr(addr)
+		return v(&ip)
+	}
+}
+
 // HostVador is a function that validates a host.
 type HostVador func(string) error
 
+// PathVador is a function that validates the decoded path of a URL.
+type PathVador func(string) error
+
+// QueryVador is a function that validates the parsed query of a URL.
+type QueryVador func(url.Values) error
+
+// queryRule is the internal form of a query rule.  Some rules need the raw
+// query, since parsing it drops the parameters that cannot be parsed.
+type queryRule func(rawQuery string) error
+
+func (q QueryVador) withRaw() queryRule {
+	return func(rawQuery string) error {
+		// Parameters that cannot be parsed are left out, the same way
+		// url.URL.Query() leaves them out.
+		query, _ := url.ParseQuery(rawQuery)
+		return q(query)
+	}
+}
+
+// fragmentRule is the internal form of a fragment rule.
+type fragmentRule func(string) error
+
+// userRule is the internal form of a userinfo rule.  The userinfo is never
+// nil.
+type userRule func(*url.Userinfo) error
+
+// portRule is the internal form of a port rule.  The port is the port from
+// the URL, which is empty if the URL does not specify one.
+type portRule func(scheme, port string) error
+
+// hostRule is the internal form of a host rule.  Rules that perform lookups
+// need the context of the check that is being run.
+type hostRule func(context.Context, string) error
+
+func (h HostVador) withContext() hostRule {
+	return func(_ context.Context, host string) error {
+		return h(host)
+	}
+}
+
 // New returns a new Checker with the provided options applied.
 func New(opts ...Option) (*Checker, error) {
 	c := Checker{
-		opts: make([]Option, 0, len(opts)),
+		maxRedirects: defaultMaxRedirects,
+		opts:         make([]Option, 0, len(opts)),
 	}
 
 	for _, opt := range opts {
 		if opt != nil {
+			before := c.ruleCounts()
 			opt.apply(&c)
+			c.nameRules(before, opt.String())
 			c.opts = append(c.opts, opt)
 		}
 	}
@@ -66,6 +150,100 @@ func New(opts ...Option) (*Checker, error) {
 	return &c, nil
 }
 
+// ruleCounts is the number of rules of each kind a Checker has.
+type ruleCounts struct {
+	scheme   int
+	user     int
+	port     int
+	path     int
+	query    int
+	fragment int
+	ipBefore int
+	host     int
+	ip       int
+	ipAllow  int
+}
+
+func (c *Checker) ruleCounts() ruleCounts {
+	return ruleCounts{
+		scheme:   len(c.schemeRules),
+		user:     len(c.userRules),
+		port:     len(c.portRules),
+		path:     len(c.pathRules),
+		query:    len(c.queryRules),
+		fragment: len(c.fragmentRules),
+		ipBefore: len(c.ipBeforeRules),
+		host:     len(c.hostRules),
+		ip:       len(c.ipRules),
+		ipAllow:  len(c.ipAllowRules),
+	}
+}
+
+// nameRules attributes every rule added since the counts were taken to the
+// named Option, so the errors they return say which Option rejected the URL.
+func (c *Checker) nameRules(from ruleCounts, name string) {
+	for i := from.scheme; i < len(c.schemeRules); i++ {
+		rule := c.schemeRules[i]
+		c.schemeRules[i] = func(s string) error {
+			return annotate(rule(s), 0, "", name)
+		}
+	}
+	for i := from.user; i < len(c.userRules); i++ {
+		rule := c.userRules[i]
+		c.userRules[i] = func(user *url.Userinfo) error {
+			return annotate(rule(user), 0, "", name)
+		}
+	}
+	for i := from.port; i < len(c.portRules); i++ {
+		rule := c.portRules[i]
+		c.portRules[i] = func(scheme, port string) error {
+			return annotate(rule(scheme, port), 0, "", name)
+		}
+	}
+	for i := from.path; i < len(c.pathRules); i++ {
+		rule := c.pathRules[i]
+		c.pathRules[i] = func(path string) error {
+			return annotate(rule(path), 0, "", name)
+		}
+	}
+	for i := from.query; i < len(c.queryRules); i++ {
+		rule := c.queryRules[i]
+		c.queryRules[i] = func(rawQuery string) error {
+			return annotate(rule(rawQuery), 0, "", name)
+		}
+	}
+	for i := from.fragment; i < len(c.fragmentRules); i++ {
+		rule := c.fragmentRules[i]
+		c.fragmentRules[i] = func(fragment string) error {
+			return annotate(rule(fragment), 0, "", name)
+		}
+	}
+	for i := from.ipBefore; i < len(c.ipBeforeRules); i++ {
+		rule := c.ipBeforeRules[i]
+		c.ipBeforeRules[i] = func(addr netip.Addr) error {
+			return annotate(rule(addr), 0, "", name)
+		}
+	}
+	for i := from.host; i < len(c.hostRules); i++ {
+		rule := c.hostRules[i]
+		c.hostRules[i] = func(ctx context.Context, host string) error {
+			return annotate(rule(ctx, host), 0, "", name)
+		}
+	}
+	for i := from.ip; i < len(c.ipRules); i++ {
+		rule := c.ipRules[i]
+		c.ipRules[i] = func(addr netip.Addr) error {
+			return annotate(rule(addr), 0, "", name)
+		}
+	}
+	for i := from.ipAllow; i < len(c.ipAllowRules); i++ {
+		rule := c.ipAllowRules[i]
+		c.ipAllowRules[i] = func(addr netip.Addr) error {
+			return annotate(rule(addr), 0, "", name)
+		}
+	}
+}
+
 // Must returns a new Checker with the provided options applied. If an error
 // occurs, it panics.
 func Must(opts ...Option) *Checker {
@@ -82,6 +260,12 @@ func (c *Checker) Legit(s string) bool {
 	return c.Text(s) == nil
 }
 
+// LegitContext returns true if the provided string is a valid URL based on
+// the provided options.  The context applies to any lookups performed.
+func (c *Checker) LegitContext(ctx context.Context, s string) bool {
+	return c.TextContext(ctx, s) == nil
+}
+
 // URLLegit returns true if the provided URL is valid based on the provided
 // options.
 func (c *Checker) URLegit(u *url.URL) bool {
@@ -91,76 +275,314 @@ func (c *Checker) URLegit(u *url.URL) bool {
 // Text returns an error if the provided string is not a valid URL based on
 // the provided options.
 func (c *Checker) Text(s string) error {
-	u, err := url.Parse(s)
+	return c.TextContext(context.Background(), s)
+}
+
+// TextContext returns an error if the provided string is not a valid URL
+// based on the provided options.  The context applies to any lookups
+// performed.
+func (c *Checker) TextContext(ctx context.Context, s string) error {
+	parse := c.parser
+	if parse == nil {
+		parse = url.Parse
+	}
+
+	u, err := parse(s)
 	if err != nil {
 		return err
 	}
-	return c.URL(u)
+	return c.URLContext(ctx, u)
 }
 
-// URLDetails returns an error if the provided URL is not valid based on
-// the provided options.
+// URL returns an error if the provided URL is not valid based on the
+// provided options.
 func (c *Checker) URL(u *url.URL) error {
+	return c.URLContext(context.Background(), u)
+}
+
+// URLContext returns an error if the provided URL is not valid based on the
+// provided options.  The context covers the entire check, including every
+// lookup made by the host rules and the resolver.
+//
+// Errors returned by the rules are *ValidationError values that describe the
+// stage, value and Option that rejected the URL.
+func (c *Checker) URLContext(ctx context.Context, u *url.URL) error {
+	var r run
+	c.check(ctx, u, c.resolver, &r)
+	return r.err()
+}
+
+// Violations returns every reason the provided URL is not valid based on the
+// provided options, instead of stopping at the first failure.  The errors are
+// joined together using errors.Join(), and each resolved IP that fails a rule
+// is reported separately.  If the URL is valid, nil is returned.
+func (c *Checker) Violations(u *url.URL) error {
+	return c.ViolationsContext(context.Background(), u)
+}
+
+// ViolationsContext is the same as Violations, but the context applies to
+// any lookups performed.
+func (c *Checker) ViolationsContext(ctx context.Context, u *url.URL) error {
+	r := run{all: true}
+	c.check(ctx, u, c.resolver, &r)
+	return errors.Join(r.errs...)
+}
+
+// run collects the failures found while checking a URL.
+type run struct {
+	// all is true when every failure should be collected, instead of
+	// stopping at the first.
+	all  bool
+	errs []error
+}
+
+// fail records the error and returns true if the check should continue.
+func (r *run) fail(err error) bool {
+	r.errs = append(r.errs, err)
+	return r.all
+}
+
+// err returns the first failure found, or nil.
+func (r *run) err() error {
+	if len(r.errs) == 0 {
+		return nil
+	}
+	return r.errs[0]
+}
+
+// check runs the rules against the URL, reporting each failure to the run.
+// The resolver is used to resolve the hostname.  The IPs that passed every
+// rule are returned, along with the normalized host they were checked as.
+func (c *Checker) check(ctx context.Context, u *url.URL, resolver ResolverContext, r *run) (string, []netip.Addr) {
 	if u == nil {
-		return ErrInvalidInput
+		r.fail(ErrInvalidInput)
+		return "", nil
 	}
 
 	scheme := strings.ToLower(u.Scheme)
 	for _, rule := range c.schemeRules {
 		err := rule(scheme)
-		if err != nil {
-			return err
+		if err != nil && !r.fail(annotate(err, StageScheme, scheme, "")) {
+			return "", nil
+		}
+	}
+
+	if u.User != nil {
+		for _, rule := range c.userRules {
+			err := rule(u.User)
+			if err != nil && !r.fail(annotate(err, StageUserinfo, "", "")) {
+				return "", nil
+			}
 		}
 	}
 
+	if !c.checkPort(scheme, urlPort(u), r) {
+		return "", nil
+	}
+
+	if !c.checkPathQueryFragment(u, r) {
+		return "", nil
+	}
+
 	host := strings.ToLower(u.Hostname())
 	if host == "" {
-		return ErrHostnameEmpty
+		r.fail(ErrHostnameEmpty)
+		return "", nil
+	}
+
+	return c.checkHost(ctx, host, resolver, r)
+}
+
+// checkPort runs the port rules, reporting each failure to the run.  If there
+// are port rules, an invalid port is rejected since the rules cannot be
+// applied to it.  It returns false if the check should stop.
+func (c *Checker) checkPort(scheme, port string, r *run) bool {
+	if len(c.portRules) == 0 {
+		return true
+	}
+
+	if port != "" {
+		if _, err := parsePort(port); err != nil {
+			return r.fail(annotate(ErrInvalidPort, StagePort, port, ""))
+		}
+	}
+
+	// Without a port there is nothing to report as the value, so the error
+	// only names the Option.
+	stage := StagePort
+	value := effectivePort(scheme, port)
+	if value == "" {
+		stage = 0
+	}
+
+	for _, rule := range c.portRules {
+		err := rule(scheme, port)
+		if err != nil && !r.fail(annotate(err, stage, value, "")) {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPathQueryFragment runs the path, query and fragment rules, reporting
+// each failure to the run.  It returns false if the check should stop.
+func (c *Checker) checkPathQueryFragment(u *url.URL, r *run) bool {
+	for _, rule := range c.pathRules {
+		err := rule(u.Path)
+		if err != nil && !r.fail(annotate(err, StagePath, u.Path, "")) {
+			return false
+		}
+	}
+
+	for _, rule := range c.queryRules {
+		err := rule(u.RawQuery)
+		if err != nil && !r.fail(annotate(err, StageQuery, "", "")) {
+			return false
+		}
+	}
+
+	for _, rule := range c.fragmentRules {
+		err := rule(u.Fragment)
+		if err != nil && !r.fail(annotate(err, StageFragment, "", "")) {
+			return false
+		}
 	}
+	return true
+}
+
+// checkHost runs the host and IP rules against the host, reporting each
+// failure to the run.  The resolver is only used if the host is not an IP
+// address.  The normalized host, as the rules checked it, and the IPs that
+// passed every rule are returned; if a rule rejected the host itself, no IPs
+// are returned.
+func (c *Checker) checkHost(ctx context.Context, host string, resolver ResolverContext, r *run) (string, []netip.Addr) {
+	var ips []netip.Addr
+	hostOK := true
+
+	if _, err := netip.ParseAddr(host); err != nil {
+		var err error
+		host, err = toASCII(host)
 
-	var ips []net.IP
-	ip := net.ParseIP(host)
+		// The root label is implied, so "localhost." is checked as
+		// "localhost".
+		if len(host) > 1 {
+			host = strings.TrimSuffix(host, ".")
+		}
+
+		if err != nil && c.invalidIDN != nil {
+			hostOK = false
+			err = &ValidationError{
+				Stage:  StageHost,
+				Value:  host,
+				Option: c.invalidIDN.String(),
+				Err:    ErrInvalidIDN,
+			}
+			if !r.fail(err) {
+				return host, nil
+			}
+		}
+	}
 
-	if ip != nil {
-		ips = []net.IP{ip}
+	ip, legacy, err := parseHostIP(host)
+	if err != nil {
+		r.fail(annotate(err, StageHost, host, ""))
+		return host, nil
+	}
+
+	if legacy && c.legacyIPv4 != nil {
+		hostOK = false
+		err = &ValidationError{
+			Stage:  StageIPLiteral,
+			Value:  host,
+			Option: c.legacyIPv4.String(),
+			Err:    ErrLegacyIPv4,
+		}
+		if !r.fail(err) {
+			return host, nil
+		}
+	}
+
+	if ip.IsValid() {
+		// Legacy notations such as 0x7f.1 are normalized to the address.
+		host = ip.String()
+		ips = []netip.Addr{ip}
 		for _, rule := range c.ipBeforeRules {
-			err := rule(&ip)
+			err := rule(ip)
 			if err != nil {
-				return err
+				hostOK = false
+				if !r.fail(annotate(err, StageIPLiteral, host, "")) {
+					return host, nil
+				}
 			}
 		}
 	} else {
 		for _, rule := range c.hostRules {
-			err := rule(host)
+			err := rule(ctx, host)
 			if err != nil {
-				return err
+				hostOK = false
+				if !r.fail(annotate(err, StageHost, host, "")) {
+					return host, nil
+				}
 			}
 		}
 
-		if c.resolver != nil {
-			var err error
+		if resolver != nil {
 			// Replace the IPs with the newly resolved IPs.
-			ips, err = c.resolver(host)
+			resolved, err := resolver(ctx, host)
 			if err != nil {
-				return err
+				r.fail(err)
+				return host, nil
 			}
+			ips = addrsFromIPs(resolved)
 		}
 	}
 
-	if len(ips) == 0 {
-		return nil
+	stage := StageResolvedIP
+	if ip.IsValid() {
+		stage = StageIPLiteral
 	}
 
+	failed := make([]bool, len(ips))
 	for _, rule := range c.ipRules {
-		for _, ip := range ips {
-			err := rule(&ip)
+		for i, ip := range ips {
+			err := rule(ip)
+			if err == nil && c.embeddedIPv4 {
+				err = checkEmbeddedIPv4(rule, ip)
+			}
 			if err != nil {
-				return err
+				failed[i] = true
+				if !r.fail(annotate(err, stage, ip.String(), "")) {
+					return host, nil
+				}
 			}
 		}
 	}
 
-	return nil
+	// The embedded IPv4 addresses are not checked against the allow rules,
+	// since an IPv6 address that is allowed must not be rejected because of
+	// the IPv4 address it carries.
+	for _, rule := range c.ipAllowRules {
+		for i, ip := range ips {
+			if err := rule(ip); err != nil {
+				failed[i] = true
+				if !r.fail(annotate(err, stage, ip.String(), "")) {
+					return host, nil
+				}
+			}
+		}
+	}
+
+	if !hostOK {
+		return host, nil
+	}
+
+	passed := make([]netip.Addr, 0, len(ips))
+	for i, ip := range ips {
+		if !failed[i] {
+			passed = append(passed, ip)
+		}
+	}
+	return host, passed
 }
 
 func (c *Checker) String() string {
@@ -4,11 +4,14 @@
 package urlegit
 
 import (
+	"context"
 	"errors"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var (
@@ -78,6 +81,140 @@ func TestURL(t *testing.T) {
 	assert.ErrorIs(err, ErrInvalidInput)
 }
 
+func TestURLContext(t *testing.T) {
+	tests := []struct {
+		description string
+		opts        []Option
+		url         string
+		cancel      bool
+		expectedErr error
+	}{
+		{
+			description: "resolver honors the deadline",
+			opts:        []Option{ForbidLoopback(), WithResolverContext(mockResolverContext)},
+			url:         mockSlowURL,
+			expectedErr: context.DeadlineExceeded,
+		}, {
+			description: "host rule resolver honors the deadline",
+			opts:        []Option{ForbidSubnetContext("10.0.0.0/8", mockResolverContext)},
+			url:         mockSlowURL,
+			expectedErr: context.DeadlineExceeded,
+		}, {
+			description: "legacy resolver checks the context first",
+			opts:        []Option{ForbidLoopback(), WithResolver(mockResolver)},
+			url:         mockPrivateURL,
+			cancel:      true,
+			expectedErr: context.Canceled,
+		}, {
+			description: "resolved within the deadline",
+			opts:        []Option{ForbidLoopback(), WithResolverContext(mockResolverContext)},
+			url:         mockPrivateURL,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert := assert.New(t)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+			defer cancel()
+			if tc.cancel {
+				cancel()
+			}
+
+			c := Must(tc.opts...)
+			err := c.TextContext(ctx, tc.url)
+
+			assert.ErrorIs(err, tc.expectedErr)
+			assert.Equal(tc.expectedErr == nil, c.LegitContext(ctx, tc.url))
+		})
+	}
+}
+
+func TestViolations(t *testing.T) {
+	tests := []struct {
+		description string
+		opts        []Option
+		url         string
+		expectedErr []error
+		values      []string
+	}{
+		{
+			description: "no violations",
+			opts:        []Option{OnlyAllowSchemes("http"), ForbidLoopback()},
+			url:         "http://example.com",
+		}, {
+			description: "nil url",
+			expectedErr: []error{ErrInvalidInput},
+		}, {
+			description: "empty hostname",
+			opts:        []Option{OnlyAllowSchemes("https")},
+			url:         "http://",
+			expectedErr: []error{ErrSchemeNotAllowed, ErrHostnameEmpty},
+			values:      []string{"http"},
+		}, {
+			description: "scheme and host",
+			opts: []Option{
+				OnlyAllowSchemes("https"),
+				ForbidDomainNames("*.com"),
+				ForbidLoopback(),
+			},
+			url:         "http://localhost.com",
+			expectedErr: []error{ErrSchemeNotAllowed, ErrDomainNotAllowed},
+			values:      []string{"http", "localhost.com"},
+		}, {
+			description: "each resolved ip",
+			opts: []Option{
+				ForbidLoopback(),
+				ForbidSubnet("0.0.0.0/0"),
+				WithResolver(mockResolver),
+			},
+			url:         mockPrivateLoopbackURL,
+			expectedErr: []error{ErrLoopback, ErrSubnetNotAllowed, ErrSubnetNotAllowed},
+			values:      []string{"127.0.0.1", "192.168.1.1", "127.0.0.1"},
+		}, {
+			description: "resolver failure",
+			opts:        []Option{ForbidLoopback(), WithResolver(mockResolver)},
+			url:         mockUnsupportedURL,
+			expectedErr: []error{errAny},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			var u *url.URL
+			if tc.url != "" {
+				var err error
+				u, err = url.Parse(tc.url)
+				require.NoError(err)
+			}
+
+			c := Must(tc.opts...)
+			err := c.Violations(u)
+			if len(tc.expectedErr) == 0 {
+				assert.NoError(err)
+				return
+			}
+
+			joined, ok := err.(interface{ Unwrap() []error })
+			require.True(ok)
+
+			errs := joined.Unwrap()
+			require.Len(errs, len(tc.expectedErr))
+			for i, expected := range tc.expectedErr {
+				assert.ErrorIs(errs[i], expected)
+			}
+
+			for i, value := range tc.values {
+				var ve *ValidationError
+				require.True(errors.As(errs[i], &ve))
+				assert.Equal(value, ve.Value)
+			}
+		})
+	}
+}
+
 func TestLegit(t *testing.T) {
 	c := Must(OnlyAllowSchemes("http"))
 	assert.Equal(t, true, c.Legit("http://example.com"))
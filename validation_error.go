@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"strings"
+)
+
+// Stage is the part of the URL that was being validated when a rule failed.
+type Stage int
+
+const (
+	// StageScheme is the validation of the URL scheme.
+	StageScheme Stage = iota + 1
+
+	// StageHost is the validation of a hostname that is not an IP address.
+	StageHost
+
+	// StageIPLiteral is the validation of a host that is an IP address.
+	StageIPLiteral
+
+	// StageResolvedIP is the validation of an IP address that a hostname
+	// resolved to.
+	StageResolvedIP
+
+	// StagePort is the validation of the URL port, or the well known port
+	// of the scheme if the URL does not specify one.  If there is neither,
+	// the error has no stage.
+	StagePort
+
+	// StageUserinfo is the validation of the URL userinfo.  The userinfo is
+	// never included in the error, since it may hold credentials.
+	StageUserinfo
+
+	// StagePath is the validation of the decoded URL path.
+	StagePath
+
+	// StageQuery is the validation of the URL query.  The query is never
+	// included in the error, since it may hold tokens or other secrets.
+	StageQuery
+
+	// StageFragment is the validation of the URL fragment.  The fragment is
+	// never included in the error, for the same reason as the query.
+	StageFragment
+)
+
+func (s Stage) String() string {
+	switch s {
+	case StageScheme:
+		return "scheme"
+	case StageHost:
+		return "host"
+	case StageIPLiteral:
+		return "IP literal"
+	case StageResolvedIP:
+		return "resolved IP"
+	case StagePort:
+		return "port"
+	case StageUserinfo:
+		return "userinfo"
+	case StagePath:
+		return "path"
+	case StageQuery:
+		return "query"
+	case StageFragment:
+		return "fragment"
+	}
+	return "unknown"
+}
+
+// ValidationError describes which rule rejected a URL and why.  The error
+// wraps the sentinel error returned by the rule, so errors.Is() continues to
+// work with ErrSubnetNotAllowed, ErrLoopback, etc.
+type ValidationError struct {
+	// Stage is the part of the URL being validated when the rule failed.
+	Stage Stage
+
+	// Value is the offending value, such as the scheme, host or IP.
+	Value string
+
+	// Pattern is the matched pattern or CIDR, if the rule matches against
+	// one.
+	Pattern string
+
+	// Option is the String() of the Option that provided the rule.
+	Option string
+
+	// Err is the underlying error.
+	Err error
+}
+
+// Error returns a description of the failure, for example:
+//
+//	subnet not allowed: resolved IP '10.0.0.1' matched '10.0.0.0/8' by ForbidSubnet('10.0.0.0/8')
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+
+	if e.Err != nil {
+		b.WriteString(e.Err.Error())
+	} else {
+		b.WriteString("validation failed")
+	}
+
+	if e.Stage != 0 || e.Value != "" {
+		b.WriteString(":")
+		if e.Stage != 0 {
+			b.WriteString(" ")
+			b.WriteString(e.Stage.String())
+		}
+		if e.Value != "" {
+			b.WriteString(" '")
+			b.WriteString(e.Value)
+			b.WriteString("'")
+		}
+	}
+	if e.Pattern != "" {
+		b.WriteString(" matched '")
+		b.WriteString(e.Pattern)
+		b.WriteString("'")
+	}
+	if e.Option != "" {
+		b.WriteString(" by ")
+		b.WriteString(e.Option)
+	}
+
+	return b.String()
+}
+
+// Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// matched returns a ValidationError for a rule that matched the pattern.
+func matched(err error, pattern string) error {
+	return &ValidationError{
+		Pattern: pattern,
+		Err:     err,
+	}
+}
+
+// annotate converts the error into a ValidationError, filling in any of the
+// fields the rule did not already provide.  A nil error is returned as nil.
+func annotate(err error, stage Stage, value, option string) error {
+	if err == nil {
+		return nil
+	}
+
+	ve, ok := err.(*ValidationError)
+	if ok {
+		// Copy so the rule's error is never modified.
+		tmp := *ve
+		ve = &tmp
+	} else {
+		ve = &ValidationError{Err: err}
+	}
+
+	if ve.Stage == 0 {
+		ve.Stage = stage
+	}
+	if ve.Value == "" {
+		ve.Value = value
+	}
+	if ve.Option == "" {
+		ve.Option = option
+	}
+
+	return ve
+}
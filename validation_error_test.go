@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2023 Comcast Cable Communications Management, LLC
+// SPDX-License-Identifier: Apache-2.0
+
+package urlegit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationError(t *testing.T) {
+	tests := []struct {
+		description string
+		opts        []Option
+		url         string
+		expectedErr error
+		expected    ValidationError
+		str         string
+	}{
+		{
+			description: "scheme",
+			opts:        []Option{OnlyAllowSchemes("https")},
+			url:         "http://example.com",
+			expectedErr: ErrSchemeNotAllowed,
+			expected: ValidationError{
+				Stage:  StageScheme,
+				Value:  "http",
+				Option: "OnlyAllowSchemes('https')",
+			},
+			str: "scheme not allowed: scheme 'http' by OnlyAllowSchemes('https')",
+		}, {
+			description: "domain name",
+			opts:        []Option{ForbidDomainNames("*.com")},
+			url:         "http://Example.com",
+			expectedErr: ErrDomainNotAllowed,
+			expected: ValidationError{
+				Stage:   StageHost,
+				Value:   "example.com",
+				Pattern: "*.com",
+				Option:  "ForbidDomainNames('*.com')",
+			},
+		}, {
+			description: "ip literal",
+			opts:        []Option{ForbidSubnet("10.0.0.0/8")},
+			url:         "http://10.1.2.3",
+			expectedErr: ErrSubnetNotAllowed,
+			expected: ValidationError{
+				Stage:   StageIPLiteral,
+				Value:   "10.1.2.3",
+				Pattern: "10.0.0.0/8",
+				Option:  "ForbidSubnet('10.0.0.0/8')",
+			},
+			str: "subnet not allowed: IP literal '10.1.2.3' matched '10.0.0.0/8' by ForbidSubnet('10.0.0.0/8')",
+		}, {
+			description: "ip literal before resolution",
+			opts:        []Option{ForbidAnyIPs()},
+			url:         "http://[::1]",
+			expectedErr: ErrIPNotAllowed,
+			expected: ValidationError{
+				Stage:  StageIPLiteral,
+				Value:  "::1",
+				Option: "ForbidAnyIPs()",
+			},
+		}, {
+			description: "resolved ip",
+			opts:        []Option{ForbidLoopback(), WithResolver(mockResolver)},
+			url:         mockPrivateLoopbackURL,
+			expectedErr: ErrLoopback,
+			expected: ValidationError{
+				Stage:  StageResolvedIP,
+				Value:  "127.0.0.1",
+				Option: "ForbidLoopback()",
+			},
+		}, {
+			description: "resolved ip from a host rule",
+			opts:        []Option{ForbidSubnet("192.168.0.0/16", mockResolver)},
+			url:         mockPrivateURL,
+			expectedErr: ErrSubnetNotAllowed,
+			expected: ValidationError{
+				Stage:   StageResolvedIP,
+				Value:   "192.168.1.1",
+				Pattern: "192.168.0.0/16",
+				Option:  "ForbidSubnet('192.168.0.0/16', resolver)",
+			},
+		}, {
+			description: "custom vador",
+			opts:        []Option{CustomHostVador(customHostVador)},
+			url:         "http://example.com",
+			expectedErr: ErrDomainNotAllowed,
+			expected: ValidationError{
+				Stage:  StageHost,
+				Value:  "example.com",
+				Option: "CustomHostVador(vador)",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			assert := assert.New(t)
+			require := require.New(t)
+
+			c := Must(tc.opts...)
+			err := c.Text(tc.url)
+			require.ErrorIs(err, tc.expectedErr)
+
+			var ve *ValidationError
+			require.True(errors.As(err, &ve))
+
+			tc.expected.Err = tc.expectedErr
+			assert.Equal(&tc.expected, ve)
+			if tc.str != "" {
+				assert.Equal(tc.str, err.Error())
+			}
+		})
+	}
+}
+
+func TestValidationErrorString(t *testing.T) {
+	assert.Equal(t, "validation failed", (&ValidationError{}).Error())
+	assert.Equal(t, "any error: host 'example.com'",
+		(&ValidationError{Stage: StageHost, Value: "example.com", Err: errAny}).Error())
+}
+
+func TestStageString(t *testing.T) {
+	assert.Equal(t, "scheme", StageScheme.String())
+	assert.Equal(t, "host", StageHost.String())
+	assert.Equal(t, "IP literal", StageIPLiteral.String())
+	assert.Equal(t, "resolved IP", StageResolvedIP.String())
+	assert.Equal(t, "port", StagePort.String())
+	assert.Equal(t, "userinfo", StageUserinfo.String())
+	assert.Equal(t, "path", StagePath.String())
+	assert.Equal(t, "query", StageQuery.String())
+	assert.Equal(t, "fragment", StageFragment.String())
+	assert.Equal(t, "unknown", Stage(0).String())
+}